@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultEndpoint = "https://api.workos.com"
+
+// Client makes authenticated requests to WorkOS API endpoints that aren't
+// covered by workos-go
+type Client struct {
+	APIKey     string
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// Error is returned for responses with a non-2xx status code
+type Error struct {
+	StatusCode int
+	RequestID  string
+	Message    string
+}
+
+func (e Error) Error() string {
+	msg := fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	if e.RequestID != "" {
+		msg = fmt.Sprintf("%s (request id: %s)", msg, e.RequestID)
+	}
+	return msg
+}
+
+func NewClient(apiKey string, endpoint string) *Client {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{
+		APIKey:     apiKey,
+		Endpoint:   strings.TrimSuffix(endpoint, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Do sends a request with an optional JSON body and decodes the JSON response into out, if not nil
+func (c *Client) Do(ctx context.Context, method string, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.Endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("User-Agent", "workos-cli")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		apiErr := Error{StatusCode: res.StatusCode, RequestID: res.Header.Get("X-Request-ID")}
+		var errBody struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &errBody) == nil {
+			apiErr.Message = errBody.Message
+		}
+		return apiErr
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// ProbeResult describes the response to a probe request
+type ProbeResult struct {
+	StatusCode int
+	// ServerTime is read from the Date header and is zero if the header is missing
+	ServerTime time.Time
+	Latency    time.Duration
+	TLS        *tls.ConnectionState
+}
+
+// Probe makes a lightweight authenticated request to check that the endpoint is
+// reachable and accepts the API key. Non-2xx responses are not treated as errors.
+func (c *Client) Probe(ctx context.Context) (ProbeResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Endpoint+"/organizations?limit=1", nil)
+	if err != nil {
+		return ProbeResult{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("User-Agent", "workos-cli")
+
+	start := time.Now()
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return ProbeResult{}, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	result := ProbeResult{
+		StatusCode: res.StatusCode,
+		Latency:    time.Since(start),
+		TLS:        res.TLS,
+	}
+	if date, err := http.ParseTime(res.Header.Get("Date")); err == nil {
+		result.ServerTime = date
+	}
+	return result, nil
+}
@@ -0,0 +1,454 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/api"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/auditlogs"
+	"github.com/workos/workos-go/v4/pkg/common"
+)
+
+const (
+	FlagActions        = "actions"
+	FlagActorIds       = "actor-ids"
+	FlagActorNames     = "actor-names"
+	FlagFile           = "file"
+	FlagIdempotencyKey = "idempotency-key"
+	FlagPath           = "path"
+	FlagRangeEnd       = "range-end"
+	FlagRangeStart     = "range-start"
+	FlagTargets        = "targets"
+
+	exportPollInterval = 2 * time.Second
+)
+
+// auditLogAction is an action type returned by the audit log actions API
+type auditLogAction struct {
+	Name      string         `json:"name"`
+	Schema    auditLogSchema `json:"schema"`
+	CreatedAt string         `json:"created_at"`
+	UpdatedAt string         `json:"updated_at"`
+}
+
+// auditLogSchema is a version of the schema for an audit log action
+type auditLogSchema struct {
+	Version   int             `json:"version"`
+	Actor     json.RawMessage `json:"actor,omitempty"`
+	Targets   json.RawMessage `json:"targets"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt string          `json:"created_at"`
+}
+
+func init() {
+	createEventCmd.Flags().String(FlagOrganization, "", "Organization the event belongs to")
+	_ = createEventCmd.MarkFlagRequired(FlagOrganization)
+	createEventCmd.Flags().StringP(FlagFile, "f", "", "file containing the event JSON (defaults to stdin)")
+	createEventCmd.Flags().String(FlagIdempotencyKey, "", "Idempotency key used to safely retry the request")
+	auditLogCmd.AddCommand(createEventCmd)
+
+	createSchemaCmd.Flags().StringP(FlagFile, "f", "", "file containing the schema JSON (defaults to stdin)")
+	auditLogSchemaCmd.AddCommand(createSchemaCmd)
+	list.AddFlags(listSchemasCmd.Flags())
+	auditLogSchemaCmd.AddCommand(listSchemasCmd)
+	auditLogCmd.AddCommand(auditLogSchemaCmd)
+	guard(createEventCmd, createSchemaCmd)
+
+	exportCmd.Flags().String(FlagOrganization, "", "Organization to export events for")
+	_ = exportCmd.MarkFlagRequired(FlagOrganization)
+	exportCmd.Flags().String(FlagRangeStart, "", "Start of the date range (RFC 3339 or YYYY-MM-DD)")
+	_ = exportCmd.MarkFlagRequired(FlagRangeStart)
+	exportCmd.Flags().String(FlagRangeEnd, "", "End of the date range (RFC 3339 or YYYY-MM-DD)")
+	_ = exportCmd.MarkFlagRequired(FlagRangeEnd)
+	exportCmd.Flags().StringSlice(FlagActions, nil, "Filter by actions")
+	exportCmd.Flags().StringSlice(FlagActorNames, nil, "Filter by actor names")
+	exportCmd.Flags().StringSlice(FlagActorIds, nil, "Filter by actor ids")
+	exportCmd.Flags().StringSlice(FlagTargets, nil, "Filter by target types")
+	exportCmd.Flags().String(FlagPath, "", "Path to download the CSV to (defaults to audit-logs-<export_id>.csv)")
+	exportCmd.Flags().Duration(FlagTimeout, 10*time.Minute, "How long to wait for the export to be ready, and then for it to download")
+	auditLogCmd.AddCommand(exportCmd)
+
+	rootCmd.AddCommand(auditLogCmd)
+}
+
+var auditLogCmd = &cobra.Command{
+	Use:   "auditlog",
+	Short: "Manage Audit Logs (events, schemas and exports).",
+	Long:  "Emit Audit Log events, manage the schemas of action types and export events to CSV.",
+}
+
+var createEventCmd = &cobra.Command{
+	Use:   "create-event",
+	Short: "Emit an audit log event",
+	Long:  "Emit an audit log event for an organization from a JSON file or stdin. The occurred_at field defaults to the current time.",
+	Example: `workos auditlog create-event --organization org_01EHZNVPK3SFK441A1RGBFSHRT -f event.json
+cat event.json | workos auditlog create-event --organization org_01EHZNVPK3SFK441A1RGBFSHRT --idempotency-key 884793cd`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+		file, err := cmd.Flags().GetString(FlagFile)
+		if err != nil {
+			return errors.New("invalid file flag")
+		}
+		idempotencyKey, err := cmd.Flags().GetString(FlagIdempotencyKey)
+		if err != nil {
+			return errors.New("invalid idempotency-key flag")
+		}
+
+		bytes, err := readFileOrStdin(file)
+		if err != nil {
+			return errors.Errorf("error reading event: %v", err)
+		}
+		var event auditlogs.Event
+		err = json.Unmarshal(bytes, &event)
+		if err != nil {
+			return errors.Errorf("invalid event: %v", err)
+		}
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now().UTC()
+		}
+
+		err = clients.auditLogs.CreateEvent(
+			context.Background(),
+			auditlogs.CreateEventOpts{
+				OrganizationID: organization,
+				Event:          event,
+				IdempotencyKey: idempotencyKey,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error creating audit log event")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Created %s event", event.Action), event)
+		return nil
+	},
+}
+
+var auditLogSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Manage audit log schemas",
+	Long:  "Create and list the schemas that define the actor, targets and metadata of each audit log action.",
+}
+
+var createSchemaCmd = &cobra.Command{
+	Use:   "create <action>",
+	Short: "Create a schema for an action",
+	Long:  "Create a new version of the schema for an action from a JSON file or stdin containing targets and optional actor and metadata definitions.",
+	Example: `workos auditlog schema create user.signed_in -f schema.json
+
+schema.json:
+{"targets": [{"type": "team"}], "metadata": {"type": "object", "properties": {"ip": {"type": "string"}}}}`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := cmd.Flags().GetString(FlagFile)
+		if err != nil {
+			return errors.New("invalid file flag")
+		}
+
+		bytes, err := readFileOrStdin(file)
+		if err != nil {
+			return errors.Errorf("error reading schema: %v", err)
+		}
+		var schema map[string]interface{}
+		err = json.Unmarshal(bytes, &schema)
+		if err != nil {
+			return errors.Errorf("invalid schema: %v", err)
+		}
+
+		var created auditLogSchema
+		err = newApiClient().Do(
+			context.Background(),
+			http.MethodPost,
+			fmt.Sprintf("/audit_logs/actions/%s/schemas", url.PathEscape(args[0])),
+			schema,
+			&created,
+		)
+		if err != nil {
+			return errors.Wrap(err, "error creating audit log schema")
+		}
+
+		printer.PrintMsg(fmt.Sprintf("Created version %d of the %s schema", created.Version, args[0]))
+		printer.Print(created)
+		return nil
+	},
+}
+
+var listSchemasCmd = &cobra.Command{
+	Use:   "list [action]",
+	Short: "List actions or the schemas of an action",
+	Long:  "List the configured audit log actions with their current schema version, or every schema version of the given action. Use --all to fetch every page.",
+	Example: `workos auditlog schema list --all
+workos auditlog schema list user.signed_in`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := newApiClient()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		query := url.Values{}
+		if limit > 0 {
+			query.Set(list.FlagLimit, strconv.Itoa(limit))
+		}
+		if before != "" {
+			query.Set(list.FlagBefore, before)
+		}
+		if order != "" {
+			query.Set(list.FlagOrder, order)
+		}
+
+		if len(args) == 0 {
+			p := printer.NewListPrinter(80, "Action", "Schema Version", "Updated At")
+			metadata, err := list.Walk(
+				listOpts,
+				func(after string) ([]auditLogAction, common.ListMetadata, error) {
+					return listAuditLogPage[auditLogAction](client, "/audit_logs/actions", query, after)
+				},
+				func(action auditLogAction) error {
+					p.Add(
+						action,
+						action.Name,
+						fmt.Sprint(action.Schema.Version),
+						action.UpdatedAt,
+					)
+					return nil
+				},
+			)
+			if err != nil {
+				return errors.Wrap(err, "error listing audit log actions")
+			}
+			if !listOpts.All {
+				p.SetCursors(metadata.Before, metadata.After)
+			}
+			p.Flush()
+			return nil
+		}
+
+		p := printer.NewListPrinter(120, "Version", "Targets", "Created At")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]auditLogSchema, common.ListMetadata, error) {
+				path := fmt.Sprintf("/audit_logs/actions/%s/schemas", url.PathEscape(args[0]))
+				return listAuditLogPage[auditLogSchema](client, path, query, after)
+			},
+			func(schema auditLogSchema) error {
+				p.Add(
+					schema,
+					fmt.Sprint(schema.Version),
+					string(schema.Targets),
+					schema.CreatedAt,
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing audit log schemas")
+		}
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+// listAuditLogPage fetches the page of an audit log list endpoint after the given cursor
+func listAuditLogPage[T any](client *api.Client, path string, query url.Values, after string) ([]T, common.ListMetadata, error) {
+	var page struct {
+		Data         []T                 `json:"data"`
+		ListMetadata common.ListMetadata `json:"list_metadata"`
+	}
+	pageQuery := url.Values{}
+	for key, values := range query {
+		pageQuery[key] = values
+	}
+	if after != "" {
+		pageQuery.Set(list.FlagAfter, after)
+	}
+	if len(pageQuery) > 0 {
+		path += "?" + pageQuery.Encode()
+	}
+	err := client.Do(context.Background(), http.MethodGet, path, nil, &page)
+	return page.Data, page.ListMetadata, err
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export audit log events to CSV",
+	Long:  "Create an export of an organization's audit log events, wait for it to be ready and download the CSV.",
+	Example: `workos auditlog export --organization org_01EHZNVPK3SFK441A1RGBFSHRT --range-start 2024-01-01 --range-end 2024-02-01
+workos auditlog export --organization org_01EHZNVPK3SFK441A1RGBFSHRT --range-start 2024-01-01T00:00:00Z --range-end 2024-01-02T00:00:00Z --actions user.signed_in --path signins.csv`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+		rangeStart, err := cmd.Flags().GetString(FlagRangeStart)
+		if err != nil {
+			return errors.New("invalid range-start flag")
+		}
+		rangeEnd, err := cmd.Flags().GetString(FlagRangeEnd)
+		if err != nil {
+			return errors.New("invalid range-end flag")
+		}
+		actions, err := cmd.Flags().GetStringSlice(FlagActions)
+		if err != nil {
+			return errors.New("invalid actions flag")
+		}
+		actorNames, err := cmd.Flags().GetStringSlice(FlagActorNames)
+		if err != nil {
+			return errors.New("invalid actor-names flag")
+		}
+		actorIds, err := cmd.Flags().GetStringSlice(FlagActorIds)
+		if err != nil {
+			return errors.New("invalid actor-ids flag")
+		}
+		targets, err := cmd.Flags().GetStringSlice(FlagTargets)
+		if err != nil {
+			return errors.New("invalid targets flag")
+		}
+		path, err := cmd.Flags().GetString(FlagPath)
+		if err != nil {
+			return errors.New("invalid path flag")
+		}
+		timeout, err := cmd.Flags().GetDuration(FlagTimeout)
+		if err != nil {
+			return errors.New("invalid timeout flag")
+		}
+
+		start, err := parseDate(rangeStart)
+		if err != nil {
+			return errors.Errorf("invalid range-start: %s", rangeStart)
+		}
+		end, err := parseDate(rangeEnd)
+		if err != nil {
+			return errors.Errorf("invalid range-end: %s", rangeEnd)
+		}
+
+		export, err := clients.auditLogs.CreateExport(
+			context.Background(),
+			auditlogs.CreateExportOpts{
+				OrganizationID: organization,
+				RangeStart:     start.Format(time.RFC3339),
+				RangeEnd:       end.Format(time.RFC3339),
+				Actions:        actions,
+				ActorNames:     actorNames,
+				ActorIds:       actorIds,
+				Targets:        targets,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error creating audit log export")
+		}
+
+		printer.PrintMsg(fmt.Sprintf("Created export %s, waiting for it to be ready...", export.ID))
+		deadline := time.Now().Add(timeout)
+		for strings.EqualFold(string(export.State), "pending") {
+			if time.Now().After(deadline) {
+				return errors.Errorf("timed out waiting for export %s", export.ID)
+			}
+			time.Sleep(exportPollInterval)
+
+			export, err = clients.auditLogs.GetExport(
+				context.Background(),
+				auditlogs.GetExportOpts{
+					ExportID: export.ID,
+				},
+			)
+			if err != nil {
+				return errors.Wrap(err, "error getting audit log export")
+			}
+		}
+		if !strings.EqualFold(string(export.State), "ready") {
+			return errors.Errorf("export %s failed with state %s", export.ID, export.State)
+		}
+
+		if path == "" {
+			path = fmt.Sprintf("audit-logs-%s.csv", export.ID)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		err = downloadFile(ctx, export.URL, path)
+		if err != nil {
+			return errors.Wrap(err, "error downloading audit log export")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Downloaded export %s to %s", export.ID, path), map[string]string{
+			"id":   export.ID,
+			"path": path,
+		})
+		return nil
+	},
+}
+
+// parseDate parses an RFC 3339 timestamp or a YYYY-MM-DD date in UTC
+func parseDate(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// downloadFile downloads fileUrl to path. The file is written to a temporary file next to
+// path that's renamed into place once the download completes, so a failed download never
+// leaves a partial file or replaces an existing one.
+func downloadFile(ctx context.Context, fileUrl string, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %s", res.Status)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Only left behind if something failed before the rename
+		_ = os.Remove(tmp.Name())
+	}()
+	_, err = io.Copy(tmp, res.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
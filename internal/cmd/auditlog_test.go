@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDownloadFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/truncated" {
+			// Promise more than is sent so the copy fails partway
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("id,action\n"))
+			return
+		}
+		_, _ = w.Write([]byte("id,action\n1,user.signed_in\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "export.csv")
+	if err := os.WriteFile(path, []byte("previous"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := downloadFile(context.Background(), server.URL+"/truncated", path); err == nil {
+		t.Fatal("expected an error for a truncated download")
+	}
+	assertFileContents(t, path, "previous")
+
+	if err := downloadFile(context.Background(), server.URL+"/complete", path); err != nil {
+		t.Fatal(err)
+	}
+	assertFileContents(t, path, "id,action\n1,user.signed_in\n")
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only the downloaded file to be left, got %d files", len(entries))
+	}
+}
+
+func assertFileContents(t *testing.T, path string, expected string) {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(contents) != expected {
+		t.Errorf("expected %q, got %q", expected, string(contents))
+	}
+}
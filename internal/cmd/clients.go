@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-go/v4/pkg/auditlogs"
+	"github.com/workos/workos-go/v4/pkg/directorysync"
+	"github.com/workos/workos-go/v4/pkg/events"
+	"github.com/workos/workos-go/v4/pkg/fga"
+	"github.com/workos/workos-go/v4/pkg/organizations"
+	"github.com/workos/workos-go/v4/pkg/portal"
+	"github.com/workos/workos-go/v4/pkg/sso"
+	"github.com/workos/workos-go/v4/pkg/usermanagement"
+)
+
+// apiClients are the workos-go clients for a single environment. Commands build them
+// with newClients instead of using the packages' DefaultClients, so the environment a
+// command runs against is never shared global state.
+type apiClients struct {
+	auditLogs      *auditlogs.Client
+	directorySync  *directorysync.Client
+	events         *events.Client
+	fga            *fga.Client
+	organizations  *organizations.Client
+	portal         *portal.Client
+	sso            *sso.Client
+	userManagement *usermanagement.Client
+}
+
+// newClients returns clients for the environment the command runs against,
+// including any --env, --api-key and --endpoint overrides
+func newClients() *apiClients {
+	GetConfigOrExit()
+	return clientsFor(activeEnv)
+}
+
+// clientsFor returns clients for env. An empty endpoint uses the workos-go default.
+func clientsFor(env config.Environment) *apiClients {
+	clients := &apiClients{
+		auditLogs:      &auditlogs.Client{APIKey: env.ApiKey},
+		directorySync:  &directorysync.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+		events:         &events.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+		fga:            &fga.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+		organizations:  &organizations.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+		portal:         &portal.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+		sso:            &sso.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+		userManagement: &usermanagement.Client{APIKey: env.ApiKey, Endpoint: env.Endpoint},
+	}
+	// The audit logs client has an endpoint per API rather than a base URL
+	if env.Endpoint != "" {
+		endpoint := strings.TrimSuffix(env.Endpoint, "/")
+		clients.auditLogs.EventsEndpoint = endpoint + "/audit_logs/events"
+		clients.auditLogs.ExportsEndpoint = endpoint + "/audit_logs/exports"
+	}
+	return clients
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-cli/internal/printer"
+)
+
+const (
+	FlagShowOrigin = "show-origin"
+	FlagList       = "list"
+)
+
+func init() {
+	viewConfigCmd.Flags().Bool(FlagShowOrigin, false, "Show the file, environment variable or flag each value came from")
+	restoreConfigCmd.Flags().Bool(FlagList, false, "List the available backups instead of restoring one")
+	configCmd.AddCommand(viewConfigCmd)
+	configCmd.AddCommand(restoreConfigCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and restore the CLI configuration",
+	Long: "Inspect the effective CLI configuration. Values are merged from command line flags, environment variables, " +
+		"a project .workos.json or .workos.yaml found in the working directory or its parents, and the user config file " +
+		"($" + config.EnvVarConfig + ", $" + config.EnvVarXdgConfig + "/workos/config.json or ~/.workos.json), in that order of precedence.",
+}
+
+var viewConfigCmd = &cobra.Command{
+	Use:     "view",
+	Short:   "Show the effective configuration",
+	Long:    "Show the effective configuration for this directory with API keys masked.",
+	Example: "workos config view --show-origin",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		showOrigin, err := cmd.Flags().GetBool(FlagShowOrigin)
+		if err != nil {
+			return errors.New("invalid show-origin flag")
+		}
+
+		settings := applyFlagOverrides(cmdConfig.Settings())
+		headers := []string{"Key", "Value"}
+		if showOrigin {
+			headers = append(headers, "Origin")
+		}
+		p := printer.NewListPrinter(120, headers...)
+		for _, setting := range settings {
+			if strings.HasSuffix(setting.Key, ".api_key") {
+				setting.Value = maskApiKey(setting.Value)
+			}
+			if !showOrigin {
+				setting.Origin = ""
+			}
+			row := []string{setting.Key, setting.Value}
+			if showOrigin {
+				row = append(row, setting.Origin)
+			}
+			p.Add(setting, row...)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var restoreConfigCmd = &cobra.Command{
+	Use:   "restore [backup]",
+	Short: "Restore the config file from a backup",
+	Long: fmt.Sprintf("Restore the user config file from one of the backups made each time it's written, or the most recent one if no backup is given. "+
+		"The last %d backups are kept in %s next to the config file, and the current file is backed up before it's replaced.", config.MaxBackups, config.BackupDirName),
+	Example: "workos config restore --list\nworkos config restore workos.json.20240102-150405.000000",
+	Args:    cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, err := cmd.Flags().GetBool(FlagList)
+		if err != nil {
+			return errors.New("invalid list flag")
+		}
+
+		backups, err := config.Backups()
+		if err != nil {
+			return err
+		}
+		if list {
+			p := printer.NewListPrinter(120, "Backup", "Created")
+			for _, backup := range backups {
+				p.Add(backup, backup.Name, backup.CreatedAt.Local().Format(time.DateTime))
+			}
+			p.Flush()
+			return nil
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		} else if len(backups) > 0 {
+			name = backups[0].Name
+		} else {
+			return errors.New("there are no config backups to restore")
+		}
+		err = config.RestoreBackup(name)
+		if err != nil {
+			return err
+		}
+
+		printer.PrintResult(fmt.Sprintf("Config restored from %s", name), map[string]string{"restored": name})
+		return nil
+	},
+}
+
+// applyFlagOverrides replaces settings with the values of the --env, --api-key and --endpoint flags
+func applyFlagOverrides(settings []config.Setting) []config.Setting {
+	overrides := map[string]config.Setting{}
+	if envOverride != "" {
+		overrides["active_environment"] = config.Setting{Value: envOverride, Origin: "flag --" + FlagEnv}
+	}
+	if apiKeyOverride != "" {
+		overrides["environments."+activeEnvName+".api_key"] = config.Setting{Value: apiKeyOverride, Origin: "flag --" + FlagApiKey}
+	}
+	if endpointOverride != "" {
+		overrides["environments."+activeEnvName+".endpoint"] = config.Setting{Value: endpointOverride, Origin: "flag --" + FlagEndpoint}
+	}
+
+	for i, setting := range settings {
+		if override, ok := overrides[setting.Key]; ok {
+			settings[i].Value = override.Value
+			settings[i].Origin = override.Origin
+			delete(overrides, setting.Key)
+		}
+	}
+	for key, override := range overrides {
+		settings = append(settings, config.Setting{Key: key, Value: override.Value, Origin: override.Origin})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
@@ -0,0 +1,416 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
+	"github.com/workos/workos-go/v4/pkg/directorysync"
+)
+
+const (
+	FlagDirectory = "directory"
+	FlagGroup     = "group"
+	FlagSearch    = "search"
+)
+
+func init() {
+	listDirectoriesCmd.Flags().String(FlagDomain, "", "Filter by domain")
+	listDirectoriesCmd.Flags().String(FlagSearch, "", "Search directories by name")
+	listDirectoriesCmd.Flags().String(FlagOrganization, "", "Filter by organization id")
+	list.AddFlags(listDirectoriesCmd.Flags())
+	directoryCmd.AddCommand(listDirectoriesCmd)
+	directoryCmd.AddCommand(getDirectoryCmd)
+	directoryCmd.AddCommand(deleteDirectoryCmd)
+	guard(deleteDirectoryCmd)
+	listDirectoryUsersCmd.Flags().String(FlagDirectory, "", "Filter by directory id")
+	listDirectoryUsersCmd.Flags().String(FlagGroup, "", "Filter by directory group id")
+	list.AddFlags(listDirectoryUsersCmd.Flags())
+	directoryCmd.AddCommand(listDirectoryUsersCmd)
+	directoryCmd.AddCommand(getDirectoryUserCmd)
+	listDirectoryGroupsCmd.Flags().String(FlagDirectory, "", "Filter by directory id")
+	listDirectoryGroupsCmd.Flags().String(FlagUser, "", "Filter by directory user id")
+	list.AddFlags(listDirectoryGroupsCmd.Flags())
+	directoryCmd.AddCommand(listDirectoryGroupsCmd)
+	directoryCmd.AddCommand(getDirectoryGroupCmd)
+	rootCmd.AddCommand(directoryCmd)
+}
+
+var directoryCmd = &cobra.Command{
+	Use:   "directory",
+	Short: "Inspect Directory Sync directories, users and groups.",
+	Long:  "List, inspect and delete directories and view the users and groups synced from them.",
+}
+
+var listDirectoriesCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List directories with optional filters",
+	Long:    "List directories, optionally filtering by domain, name or organization.",
+	Example: "workos directory list --organization org_01EHZNVPK3SFK441A1RGBFSHRT",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		domain, err := cmd.Flags().GetString(FlagDomain)
+		if err != nil {
+			return errors.New("invalid domain flag")
+		}
+		search, err := cmd.Flags().GetString(FlagSearch)
+		if err != nil {
+			return errors.New("invalid search flag")
+		}
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+
+		p := printer.NewListPrinter(120, "ID", "Name", "Type", "State", "Organization ID")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]directorysync.Directory, common.ListMetadata, error) {
+				directories, err := clients.directorySync.ListDirectories(
+					context.Background(),
+					directorysync.ListDirectoriesOpts{
+						Domain:         domain,
+						Search:         search,
+						OrganizationID: organization,
+						Limit:          limit,
+						Before:         before,
+						After:          after,
+						Order:          directorysync.Order(order),
+					},
+				)
+				return directories.Data, directories.ListMetadata, err
+			},
+			func(directory directorysync.Directory) error {
+				p.Add(
+					directory,
+					directory.ID,
+					directory.Name,
+					string(directory.Type),
+					string(directory.State),
+					directory.OrganizationID,
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing directories")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var getDirectoryCmd = &cobra.Command{
+	Use:     "get <directory_id>",
+	Short:   "Get a directory",
+	Long:    "Get a directory by id. Find the directory's id by listing your directories.",
+	Example: "workos directory get directory_01ECAZ4NV9QMV47GW873HDCX74",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		directory, err := clients.directorySync.GetDirectory(
+			context.Background(),
+			directorysync.GetDirectoryOpts{
+				Directory: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error getting directory")
+		}
+
+		printer.Print(directory)
+		return nil
+	},
+}
+
+var deleteDirectoryCmd = &cobra.Command{
+	Use:     "delete <directory_id>",
+	Short:   "Delete a directory",
+	Long:    "Delete a directory by id. This also deletes the users and groups synced from it.",
+	Example: "workos directory delete directory_01ECAZ4NV9QMV47GW873HDCX74",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		directoryId := args[0]
+		err := clients.directorySync.DeleteDirectory(
+			context.Background(),
+			directorysync.DeleteDirectoryOpts{
+				Directory: directoryId,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error deleting directory")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Deleted directory %s", directoryId), deletedResult{ID: directoryId, Deleted: true})
+		return nil
+	},
+}
+
+var listDirectoryUsersCmd = &cobra.Command{
+	Use:   "list-users",
+	Short: "List directory users",
+	Long:  "List the users synced from a directory, or the members of a directory group.",
+	Example: `workos directory list-users --directory directory_01ECAZ4NV9QMV47GW873HDCX74
+workos directory list-users --group directory_group_01E1JJS84MFPPQ3G655FHTKX6Z --all`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		directory, err := cmd.Flags().GetString(FlagDirectory)
+		if err != nil {
+			return errors.New("invalid directory flag")
+		}
+		group, err := cmd.Flags().GetString(FlagGroup)
+		if err != nil {
+			return errors.New("invalid group flag")
+		}
+		if directory == "" && group == "" {
+			return errors.New("either --directory or --group is required")
+		}
+
+		p := printer.NewListPrinter(120, "ID", "Name", "Email", "State", "Groups")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]directorysync.User, common.ListMetadata, error) {
+				users, err := clients.directorySync.ListUsers(
+					context.Background(),
+					directorysync.ListUsersOpts{
+						Directory: directory,
+						Group:     group,
+						Limit:     limit,
+						Before:    before,
+						After:     after,
+						Order:     directorysync.Order(order),
+					},
+				)
+				return users.Data, users.ListMetadata, err
+			},
+			func(user directorysync.User) error {
+				var groups []string
+				for _, g := range user.Groups {
+					groups = append(groups, g.Name)
+				}
+
+				p.Add(
+					user,
+					user.ID,
+					fmt.Sprintf("%s %s", user.FirstName, user.LastName),
+					directoryUserPrimaryEmail(user),
+					string(user.State),
+					strings.Join(groups, ", "),
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing directory users")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var getDirectoryUserCmd = &cobra.Command{
+	Use:     "get-user <directory_user_id>",
+	Short:   "Get a directory user",
+	Long:    "Get a directory user by id, including the custom and raw attributes received from the directory provider.",
+	Example: "workos directory get-user directory_user_01E1JG7J09H96KYP8HM9B0G5SJ",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		user, err := clients.directorySync.GetUser(
+			context.Background(),
+			directorysync.GetUserOpts{
+				User: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error getting directory user")
+		}
+
+		if !printer.IsTable() {
+			printer.Print(user)
+			return nil
+		}
+
+		var groups []string
+		for _, g := range user.Groups {
+			groups = append(groups, fmt.Sprintf("%s (%s)", g.Name, g.ID))
+		}
+		tbl := printer.NewTable(120).Headers(
+			printer.TableHeader("Field"),
+			printer.TableHeader("Value"),
+		)
+		tbl.Row("ID", user.ID)
+		tbl.Row("IdP ID", user.IdpID)
+		tbl.Row("Directory ID", user.DirectoryID)
+		tbl.Row("Organization ID", user.OrganizationID)
+		tbl.Row("Name", fmt.Sprintf("%s %s", user.FirstName, user.LastName))
+		tbl.Row("Email", directoryUserPrimaryEmail(user))
+		tbl.Row("Job Title", user.JobTitle)
+		tbl.Row("State", string(user.State))
+		tbl.Row("Groups", strings.Join(groups, "\n"))
+		printer.PrintMsg(tbl.Render())
+
+		customAttributes, err := json.MarshalIndent(user.CustomAttributes, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "error reading custom attributes")
+		}
+		printer.PrintMsg("Custom Attributes:")
+		printer.PrintMsg(string(customAttributes))
+
+		rawAttributes, err := json.MarshalIndent(user.RawAttributes, "", "    ")
+		if err != nil {
+			return errors.Wrap(err, "error reading raw attributes")
+		}
+		printer.PrintMsg("Raw Attributes:")
+		printer.PrintMsg(string(rawAttributes))
+		return nil
+	},
+}
+
+var listDirectoryGroupsCmd = &cobra.Command{
+	Use:   "list-groups",
+	Short: "List directory groups",
+	Long:  "List the groups synced from a directory, or the groups a directory user belongs to.",
+	Example: `workos directory list-groups --directory directory_01ECAZ4NV9QMV47GW873HDCX74
+workos directory list-groups --user directory_user_01E1JG7J09H96KYP8HM9B0G5SJ`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		directory, err := cmd.Flags().GetString(FlagDirectory)
+		if err != nil {
+			return errors.New("invalid directory flag")
+		}
+		user, err := cmd.Flags().GetString(FlagUser)
+		if err != nil {
+			return errors.New("invalid user flag")
+		}
+		if directory == "" && user == "" {
+			return errors.New("either --directory or --user is required")
+		}
+
+		p := printer.NewListPrinter(120, "ID", "Name", "Directory ID")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]directorysync.Group, common.ListMetadata, error) {
+				groups, err := clients.directorySync.ListGroups(
+					context.Background(),
+					directorysync.ListGroupsOpts{
+						Directory: directory,
+						User:      user,
+						Limit:     limit,
+						Before:    before,
+						After:     after,
+						Order:     directorysync.Order(order),
+					},
+				)
+				return groups.Data, groups.ListMetadata, err
+			},
+			func(group directorysync.Group) error {
+				p.Add(
+					group,
+					group.ID,
+					group.Name,
+					group.DirectoryID,
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing directory groups")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var getDirectoryGroupCmd = &cobra.Command{
+	Use:     "get-group <directory_group_id>",
+	Short:   "Get a directory group",
+	Long:    "Get a directory group by id, including the raw attributes received from the directory provider.",
+	Example: "workos directory get-group directory_group_01E1JJS84MFPPQ3G655FHTKX6Z",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		group, err := clients.directorySync.GetGroup(
+			context.Background(),
+			directorysync.GetGroupOpts{
+				Group: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error getting directory group")
+		}
+
+		printer.Print(group)
+		return nil
+	},
+}
+
+func directoryUserPrimaryEmail(user directorysync.User) string {
+	for _, email := range user.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(user.Emails) > 0 {
+		return user.Emails[0].Value
+	}
+	return ""
+}
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/api"
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-cli/internal/printer"
+)
+
+const (
+	ApiKeyPrefixSandbox    = "sk_test_"
+	ApiKeyPrefixProduction = "sk_live_"
+
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+	CheckSkip = "skip"
+
+	// maxClockSkew is how far the local clock may drift from the API's before
+	// time-sensitive operations like webhook signature checks start failing
+	maxClockSkew = time.Minute
+	probeTimeout = 10 * time.Second
+)
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorCheck is the outcome of a single diagnostic
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration problems",
+	Long: "Check the config file, the active environment and its API key, and connectivity to the environment's endpoint. " +
+		"Each failed check comes with a suggestion for fixing it. Exits non-zero if any check fails.",
+	Example: "workos doctor --env production",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		checks := []doctorCheck{checkConfigFile()}
+		checks = append(checks, checkEnvironment()...)
+
+		failed := 0
+		for _, check := range checks {
+			if check.Status == CheckFail {
+				failed++
+			}
+		}
+		if !printer.IsTable() {
+			printer.Print(checks)
+		} else {
+			for _, check := range checks {
+				printDoctorCheck(check)
+			}
+		}
+		if failed > 0 {
+			printer.PrintMsg(fmt.Sprintf("\n%d of %d checks failed", failed, len(checks)))
+			os.Exit(1)
+		}
+		printer.PrintMsg("\nNo problems found")
+	},
+}
+
+func printDoctorCheck(check doctorCheck) {
+	var mark string
+	switch check.Status {
+	case CheckPass:
+		mark = printer.GreenText(printer.Checkmark)
+	case CheckFail:
+		mark = printer.RedText(printer.Cross)
+	case CheckWarn:
+		mark = printer.YellowText("!")
+	default:
+		mark = printer.QuestionMark
+	}
+	line := fmt.Sprintf("%s %s", mark, check.Name)
+	if check.Detail != "" {
+		line = fmt.Sprintf("%s: %s", line, check.Detail)
+	}
+	printer.PrintMsg(line)
+	if check.Fix != "" {
+		printer.PrintMsg("    " + check.Fix)
+	}
+}
+
+// checkConfigFile checks that the config file only contains known keys. The file has
+// already been parsed by the time commands run, so it's known to be valid JSON.
+func checkConfigFile() doctorCheck {
+	check := doctorCheck{Name: "Config file"}
+	path, err := config.FilePath()
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = err.Error()
+		return check
+	}
+	check.Detail = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = err.Error()
+		check.Fix = "Run 'workos init' to create it"
+		return check
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var cfg config.Config
+	err = decoder.Decode(&cfg)
+	if err != nil {
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("%s: %v", path, err)
+		check.Fix = "Remove or rename the key. Environments support endpoint, name, type, api_key and api_key_ref"
+		return check
+	}
+	check.Status = CheckPass
+	return check
+}
+
+// checkEnvironment checks the environment commands run against, its API key and its endpoint
+func checkEnvironment() []doctorCheck {
+	envCheck := doctorCheck{Name: "Active environment"}
+	env, ok := cmdConfig.Environments[activeEnvName]
+	switch {
+	case activeEnvName == "":
+		envCheck.Status = CheckFail
+		envCheck.Detail = "no active environment is set"
+		envCheck.Fix = "Run 'workos env switch' to select an environment, or 'workos init' to add one"
+	case !ok:
+		envCheck.Status = CheckFail
+		envCheck.Detail = fmt.Sprintf("environment %s is not configured", activeEnvName)
+		envCheck.Fix = "Run 'workos env switch' to select a configured environment"
+	case env.Type != EnvironmentTypeProduction && env.Type != EnvironmentTypeSandbox:
+		envCheck.Status = CheckWarn
+		envCheck.Detail = fmt.Sprintf("%s has unknown type %q", activeEnvName, env.Type)
+		envCheck.Fix = fmt.Sprintf("Set the type to %s or %s in the config file", EnvironmentTypeProduction, EnvironmentTypeSandbox)
+	default:
+		envCheck.Status = CheckPass
+		envCheck.Detail = fmt.Sprintf("%s (%s)", activeEnvName, env.Type)
+	}
+	if envCheck.Status == CheckFail {
+		return []doctorCheck{envCheck, skippedCheck("API key"), skippedCheck("Endpoint"), skippedCheck("TLS"), skippedCheck("Clock"), skippedCheck("Authentication")}
+	}
+
+	checks := []doctorCheck{envCheck, checkApiKey(env)}
+	if activeEnv.ApiKey == "" {
+		return append(checks, skippedCheck("Endpoint"), skippedCheck("TLS"), skippedCheck("Clock"), skippedCheck("Authentication"))
+	}
+	return append(checks, checkEndpoint()...)
+}
+
+func checkApiKey(env config.Environment) doctorCheck {
+	check := doctorCheck{Name: "API key"}
+	apiKey := activeEnv.ApiKey
+	if apiKey == "" {
+		check.Status = CheckFail
+		check.Detail = "no API key is configured"
+		check.Fix = "Run 'workos env add' to add the environment with its API key"
+		if env.ApiKeyRef != "" {
+			if _, err := env.ResolveApiKey(); err != nil {
+				check.Detail = err.Error()
+			}
+			check.Fix = fmt.Sprintf("Check that %s is still in the secret store, or run 'workos env add' to store the key again", env.ApiKeyRef)
+		}
+		return check
+	}
+
+	switch {
+	case strings.HasPrefix(apiKey, ApiKeyPrefixSandbox) && env.Type == EnvironmentTypeProduction:
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("sandbox key (%s...) configured for a %s environment", ApiKeyPrefixSandbox, EnvironmentTypeProduction)
+		check.Fix = fmt.Sprintf("Use a production key or change the environment's type to %s", EnvironmentTypeSandbox)
+	case strings.HasPrefix(apiKey, ApiKeyPrefixProduction) && env.Type == EnvironmentTypeSandbox:
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("production key (%s...) configured for a %s environment", ApiKeyPrefixProduction, EnvironmentTypeSandbox)
+		check.Fix = fmt.Sprintf("Use a sandbox key or change the environment's type to %s", EnvironmentTypeProduction)
+	case !strings.HasPrefix(apiKey, ApiKeyPrefixSandbox) && !strings.HasPrefix(apiKey, ApiKeyPrefixProduction):
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("key doesn't start with %s or %s", ApiKeyPrefixSandbox, ApiKeyPrefixProduction)
+		check.Fix = "Check that the key was copied correctly from the WorkOS dashboard"
+	default:
+		check.Status = CheckPass
+		check.Detail = "matches the environment type"
+	}
+	return check
+}
+
+// checkEndpoint makes a probe request to check reachability, TLS, clock skew and that the key is accepted
+func checkEndpoint() []doctorCheck {
+	client := api.NewClient(activeEnv.ApiKey, activeEnv.Endpoint)
+	client.HTTPClient.Timeout = probeTimeout
+	endpointCheck := doctorCheck{Name: "Endpoint", Detail: client.Endpoint}
+	tlsCheck := doctorCheck{Name: "TLS"}
+
+	res, err := client.Probe(context.Background())
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			endpointCheck.Status = CheckPass
+			tlsCheck.Status = CheckFail
+			tlsCheck.Detail = certErr.Error()
+			tlsCheck.Fix = "Check the system's trusted certificates and any proxy that intercepts HTTPS traffic"
+			return []doctorCheck{endpointCheck, tlsCheck, skippedCheck("Clock"), skippedCheck("Authentication")}
+		}
+		endpointCheck.Status = CheckFail
+		endpointCheck.Detail = fmt.Sprintf("%s: %v", client.Endpoint, err)
+		endpointCheck.Fix = "Check your network connection and the environment's endpoint"
+		return []doctorCheck{endpointCheck, skippedCheck("TLS"), skippedCheck("Clock"), skippedCheck("Authentication")}
+	}
+	endpointCheck.Status = CheckPass
+	endpointCheck.Detail = fmt.Sprintf("%s (%s)", client.Endpoint, res.Latency.Round(time.Millisecond))
+
+	if res.TLS != nil {
+		tlsCheck.Status = CheckPass
+		tlsCheck.Detail = tls.VersionName(res.TLS.Version)
+	} else {
+		tlsCheck.Status = CheckSkip
+		tlsCheck.Detail = "endpoint doesn't use HTTPS"
+	}
+
+	clockCheck := doctorCheck{Name: "Clock"}
+	if res.ServerTime.IsZero() {
+		clockCheck.Status = CheckSkip
+		clockCheck.Detail = "the endpoint didn't report its time"
+	} else {
+		// The Date header only has second precision
+		skew := time.Since(res.ServerTime).Truncate(time.Second)
+		clockCheck.Detail = fmt.Sprintf("%s from the server's time", skew)
+		if skew.Abs() > maxClockSkew {
+			clockCheck.Status = CheckWarn
+			clockCheck.Fix = "Sync the system clock, e.g. by enabling network time"
+		} else {
+			clockCheck.Status = CheckPass
+		}
+	}
+
+	authCheck := doctorCheck{Name: "Authentication"}
+	switch status := probeKeyStatus(res); status {
+	case KeyStatusValid:
+		authCheck.Status = CheckPass
+		authCheck.Detail = "the API key was accepted"
+	case KeyStatusInvalid:
+		authCheck.Status = CheckFail
+		authCheck.Detail = "the API key was rejected"
+		authCheck.Fix = "The key may have been revoked. Create a new key in the WorkOS dashboard and run 'workos env add'"
+	default:
+		authCheck.Status = CheckWarn
+		authCheck.Detail = fmt.Sprintf("unexpected response: %s", status)
+	}
+	return []doctorCheck{endpointCheck, tlsCheck, clockCheck, authCheck}
+}
+
+func skippedCheck(name string) doctorCheck {
+	return doctorCheck{Name: name, Status: CheckSkip, Detail: "skipped"}
+}
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/charmbracelet/huh"
+	"github.com/workos/workos-cli/internal/api"
 	"github.com/workos/workos-cli/internal/printer"
+	"net/http"
+	"os"
 	"regexp"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/workos/workos-cli/internal/config"
@@ -16,13 +23,110 @@ const (
 	EnvironmentTypeProduction = "Production"
 	EnvironmentTypeSandbox    = "Sandbox"
 	FlagEndpoint              = "endpoint"
+	FlagSecretStore           = "secret-store"
+	FlagValidate              = "validate"
+
+	KeyStatusValid       = "valid"
+	KeyStatusInvalid     = "invalid"
+	KeyStatusUnreachable = "unreachable"
+	KeyStatusMissing     = "missing"
 )
 
+// environmentSummary is the printable form of an environment. It only includes a masked
+// API key, or the reference to it when the key is kept in a secret store.
+type environmentSummary struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Active    bool   `json:"active"`
+	ApiKey    string `json:"api_key,omitempty"`
+	KeyStatus string `json:"key_status,omitempty"`
+}
+
+func newEnvironmentSummary(cfg *config.Config, name string) environmentSummary {
+	env := cfg.Environments[name]
+	apiKey := maskApiKey(env.ApiKey)
+	if env.ApiKeyRef != "" {
+		apiKey = env.ApiKeyRef
+	}
+	return environmentSummary{
+		Name:     name,
+		Type:     env.Type,
+		Endpoint: env.Endpoint,
+		Active:   cfg.ActiveEnvironment == name,
+		ApiKey:   apiKey,
+	}
+}
+
+// maskApiKey hides all but the prefix and the last four characters of an API key
+func maskApiKey(key string) string {
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 12 {
+		return strings.Repeat("*", len(key))
+	}
+	prefix := ""
+	if i := strings.LastIndex(key[:8], "_"); i >= 0 {
+		prefix = key[:i+1]
+	}
+	return prefix + strings.Repeat("*", 8) + key[len(key)-4:]
+}
+
+// validateEnvironments probes the API key of each environment concurrently and
+// records whether it's valid, invalid or the endpoint is unreachable
+func validateEnvironments(cfg *config.Config, summaries []environmentSummary) {
+	var wg sync.WaitGroup
+	for i := range summaries {
+		env := cfg.Environments[summaries[i].Name]
+		// Resolved before probing concurrently, so the file store only asks for its passphrase once
+		apiKey, err := env.ResolveApiKey()
+		if err != nil || apiKey == "" {
+			summaries[i].KeyStatus = KeyStatusMissing
+			continue
+		}
+		wg.Add(1)
+		go func(summary *environmentSummary) {
+			defer wg.Done()
+			client := api.NewClient(apiKey, env.Endpoint)
+			client.HTTPClient.Timeout = probeTimeout
+			res, err := client.Probe(context.Background())
+			if err != nil {
+				summary.KeyStatus = KeyStatusUnreachable
+				return
+			}
+			summary.KeyStatus = probeKeyStatus(res)
+		}(&summaries[i])
+	}
+	wg.Wait()
+}
+
+// probeKeyStatus classifies the response to a probe request. Only a 401 means the key was
+// rejected. Any other error status, e.g. a 403 for a key that can't list organizations, is
+// returned as is.
+func probeKeyStatus(res api.ProbeResult) string {
+	switch {
+	case res.StatusCode == http.StatusUnauthorized:
+		return KeyStatusInvalid
+	case res.StatusCode >= 200 && res.StatusCode <= 299:
+		return KeyStatusValid
+	default:
+		return fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+	}
+}
+
 func init() {
 	envCmd.AddCommand(addEnvCmd)
 	addEnvCmd.Flags().String(FlagEndpoint, "", "Override the API endpoint")
+	addEnvCmd.Flags().String(FlagSecretStore, config.SecretStoreKeyring, "Where to store the API key ("+strings.Join(config.SecretStores, ", ")+")")
 	envCmd.AddCommand(removeEnvCmd)
 	envCmd.AddCommand(switchEnvCmd)
+	migrateSecretsCmd.Flags().String(FlagSecretStore, config.SecretStoreKeyring, "Where to store the API keys ("+strings.Join([]string{config.SecretStoreKeyring, config.SecretStoreFile}, ", ")+")")
+	envCmd.AddCommand(migrateSecretsCmd)
+	listEnvCmd.Flags().Bool(FlagValidate, false, "Check each environment's API key against its endpoint")
+	envCmd.AddCommand(listEnvCmd)
+	showEnvCmd.Flags().Bool(FlagValidate, false, "Check the environment's API key against its endpoint")
+	envCmd.AddCommand(showEnvCmd)
 	rootCmd.AddCommand(envCmd)
 }
 
@@ -33,7 +137,10 @@ var envCmd = &cobra.Command{
 	Example: `
 workos env add
 workos env remove
-workos env switch`,
+workos env switch
+workos env list
+workos env show production --validate
+workos env migrate-secrets`,
 	Args: cobra.NoArgs,
 }
 
@@ -57,6 +164,10 @@ var addEnvCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		secretStore, err := getSecretStore(cmd)
+		if err != nil {
+			return err
+		}
 
 		if len(args) > 0 {
 			name = args[0]
@@ -109,22 +220,62 @@ var addEnvCmd = &cobra.Command{
 		if len(cfg.Environments) == 0 {
 			cfg.Environments = make(map[string]config.Environment)
 		}
-		cfg.Environments[name] = config.Environment{
+		env := config.Environment{
 			ApiKey:   apiKey,
 			Name:     name,
 			Type:     envType,
 			Endpoint: endpoint,
 		}
+		err = env.StoreApiKey(secretStore)
+		if err != nil {
+			return err
+		}
+		previous, replaced := cfg.Environments[name]
+		cfg.Environments[name] = env
 		err = cfg.Write()
 		if err != nil {
 			return err
 		}
+		if replaced {
+			deleteReplacedApiKey(previous, env)
+		}
 
-		printer.PrintMsg(fmt.Sprintf("Environment %s added", name))
+		printer.PrintResult(fmt.Sprintf("Environment %s added", name), newEnvironmentSummary(cfg, name))
 		return nil
 	},
 }
 
+// deleteReplacedApiKey removes the API key of an environment that was replaced from its
+// secret store, unless the new environment's key was written to the same place
+func deleteReplacedApiKey(previous config.Environment, env config.Environment) {
+	if previous.ApiKeyRef == "" || previous.ApiKeyRef == env.ApiKeyRef {
+		return
+	}
+	err := previous.DeleteApiKey()
+	if err != nil {
+		printer.PrintWarning(fmt.Sprintf("unable to delete the previous stored api key for %s: %v", env.Name, err))
+	}
+}
+
+// getSecretStore returns the --secret-store flag. When it's left at its keyring default and no
+// keyring can be reached, it falls back to the encrypted file store if a passphrase can be read,
+// or else to plaintext, with a warning either way.
+func getSecretStore(cmd *cobra.Command) (string, error) {
+	secretStore, err := cmd.Flags().GetString(FlagSecretStore)
+	if err != nil {
+		return "", err
+	}
+	if secretStore != config.SecretStoreKeyring || cmd.Flags().Changed(FlagSecretStore) || config.KeyringAvailable() {
+		return secretStore, nil
+	}
+	if os.Getenv(config.EnvVarSecretsPassphrase) != "" || isInteractive() {
+		printer.PrintWarning(fmt.Sprintf("no OS keyring is available, storing the API key in ~/%s instead", config.SecretsFileName))
+		return config.SecretStoreFile, nil
+	}
+	printer.PrintWarning(fmt.Sprintf("no OS keyring is available, storing the API key in plaintext in the config file instead (set %s to use the encrypted file store)", config.EnvVarSecretsPassphrase))
+	return config.SecretStorePlaintext, nil
+}
+
 var removeEnvCmd = &cobra.Command{
 	Use:     "remove [name]",
 	Short:   "Remove a configured environment",
@@ -157,13 +308,18 @@ var removeEnvCmd = &cobra.Command{
 			return errors.New("the specified environment does not exist")
 		}
 
+		err := config.Environments[name].DeleteApiKey()
+		if err != nil {
+			printer.PrintWarning(fmt.Sprintf("unable to delete stored api key: %v", err))
+		}
+
 		delete(config.Environments, name)
-		err := config.Write()
+		err = config.Write()
 		if err != nil {
 			return err
 		}
 
-		printer.PrintMsg(fmt.Sprintf("Environment %s removed\n", name))
+		printer.PrintResult(fmt.Sprintf("Environment %s removed\n", name), deletedResult{ID: name, Deleted: true})
 		return nil
 	},
 }
@@ -222,7 +378,135 @@ var switchEnvCmd = &cobra.Command{
 		if activeEnv.Endpoint != "" {
 			selectedEnvLabel = fmt.Sprintf("%s [%s]", selectedEnvLabel, activeEnv.Endpoint)
 		}
-		printer.PrintMsg(fmt.Sprintf("Switched to environment %s\n", selectedEnvLabel))
+		printer.PrintResult(fmt.Sprintf("Switched to environment %s\n", selectedEnvLabel), newEnvironmentSummary(config, selectedEnvironment))
+		if origin := config.OverriddenBy("active_environment"); origin != "" {
+			printer.PrintWarning(fmt.Sprintf("the active environment is also set by %s, which takes precedence", origin))
+		}
+		return nil
+	},
+}
+
+var listEnvCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List configured environments",
+	Long:    "List the configured environments with their API keys masked. The active environment is marked with *.",
+	Example: "workos env list --validate",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		validate, err := cmd.Flags().GetBool(FlagValidate)
+		if err != nil {
+			return errors.New("invalid validate flag")
+		}
+
+		names := make([]string, 0, len(cmdConfig.Environments))
+		for name := range cmdConfig.Environments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		summaries := make([]environmentSummary, 0, len(names))
+		for _, name := range names {
+			summaries = append(summaries, newEnvironmentSummary(cmdConfig, name))
+		}
+		if validate {
+			validateEnvironments(cmdConfig, summaries)
+		}
+
+		headers := []string{"Name", "Type", "Endpoint", "Active", "API Key"}
+		if validate {
+			headers = append(headers, "Key Status")
+		}
+		p := printer.NewListPrinter(120, headers...)
+		for _, summary := range summaries {
+			active := ""
+			if summary.Active {
+				active = "*"
+			}
+			row := []string{summary.Name, summary.Type, summary.Endpoint, active, summary.ApiKey}
+			if validate {
+				row = append(row, summary.KeyStatus)
+			}
+			p.Add(summary, row...)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var showEnvCmd = &cobra.Command{
+	Use:     "show <name>",
+	Short:   "Show a configured environment",
+	Long:    "Show a configured environment with its API key masked.",
+	Example: "workos env show production --validate",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		validate, err := cmd.Flags().GetBool(FlagValidate)
+		if err != nil {
+			return errors.New("invalid validate flag")
+		}
+		name := args[0]
+		if _, ok := cmdConfig.Environments[name]; !ok {
+			return errors.New("the specified environment does not exist")
+		}
+
+		summaries := []environmentSummary{newEnvironmentSummary(cmdConfig, name)}
+		if validate {
+			validateEnvironments(cmdConfig, summaries)
+		}
+		printer.Print(summaries[0])
+		return nil
+	},
+}
+
+var migrateSecretsCmd = &cobra.Command{
+	Use:     "migrate-secrets",
+	Short:   "Move plaintext API keys into a secret store",
+	Long:    "Move API keys stored in plaintext in ~/.workos.json into the OS keyring or an encrypted file, leaving only a reference in the config file.",
+	Example: "workos env migrate-secrets --secret-store file",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := GetConfigOrExit()
+
+		secretStore, err := cmd.Flags().GetString(FlagSecretStore)
+		if err != nil {
+			return err
+		}
+		if secretStore == config.SecretStorePlaintext {
+			return errors.New("secrets can only be migrated to the keyring or file store")
+		}
+
+		migrated := []string{}
+		for name, env := range cfg.Environments {
+			// Environments using a secret store already had their key resolved at startup
+			if name == config.EnvVarHeadlessMode || env.ApiKeyRef != "" || env.ApiKey == "" {
+				continue
+			}
+			// Keys set by an environment variable or a project file aren't in the user file
+			if cfg.OverriddenBy("environments."+name+".api_key") != "" {
+				continue
+			}
+			if env.Name == "" {
+				env.Name = name
+			}
+			err = env.StoreApiKey(secretStore)
+			if err != nil {
+				return err
+			}
+			cfg.Environments[name] = env
+			migrated = append(migrated, name)
+		}
+
+		if len(migrated) == 0 {
+			printer.PrintResult("No plaintext API keys to migrate", migrated)
+			return nil
+		}
+
+		err = cfg.Write()
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(migrated)
+		printer.PrintResult(fmt.Sprintf("Moved API keys for %s to the %s store", strings.Join(migrated, ", "), secretStore), migrated)
 		return nil
 	},
 }
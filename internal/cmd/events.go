@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/events"
+)
+
+const (
+	FlagEvents   = "events"
+	FlagInterval = "interval"
+	FlagReset    = "reset"
+	FlagSince    = "since"
+)
+
+func init() {
+	tailEventsCmd.Flags().StringSlice(FlagEvents, []string{}, "Event types to tail (e.g. dsync.user.created,connection.activated)")
+	tailEventsCmd.Flags().String(FlagOrganization, "", "Only tail events for this organization id")
+	tailEventsCmd.Flags().Duration(FlagInterval, 5*time.Second, "How often to poll for new events")
+	tailEventsCmd.Flags().Duration(FlagSince, 0, "When there is no saved cursor, start this far in the past instead of now (e.g. 1h)")
+	tailEventsCmd.Flags().Bool(FlagReset, false, "Discard the saved cursor for the active environment and these filters before tailing")
+	_ = tailEventsCmd.MarkFlagRequired(FlagEvents)
+	eventsCmd.AddCommand(tailEventsCmd)
+	rootCmd.AddCommand(eventsCmd)
+}
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Stream events from the Events API.",
+	Long:  "Follow organization, directory and connection activity through the Events API.",
+}
+
+var tailEventsCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream new events as they happen",
+	Long: "Poll the Events API for the chosen event types and print new events as they appear. " +
+		"The last cursor is saved per environment and set of event types in ~/" + config.CursorsFileName + " so restarts resume where they left off. " +
+		"Use --output ndjson to print one JSON event per line.",
+	Example: "workos events tail --events dsync.user.created,dsync.user.updated --output ndjson",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		eventTypes, err := cmd.Flags().GetStringSlice(FlagEvents)
+		if err != nil {
+			return errors.New("invalid events flag")
+		}
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+		interval, err := cmd.Flags().GetDuration(FlagInterval)
+		if err != nil {
+			return errors.New("invalid interval flag")
+		}
+		since, err := cmd.Flags().GetDuration(FlagSince)
+		if err != nil {
+			return errors.New("invalid since flag")
+		}
+		reset, err := cmd.Flags().GetBool(FlagReset)
+		if err != nil {
+			return errors.New("invalid reset flag")
+		}
+
+		cursorKey := eventsCursorKey(activeEnvName, eventTypes, organization)
+		if reset {
+			if err := config.SaveEventsCursor(cursorKey, ""); err != nil {
+				return errors.Wrap(err, "error resetting cursor")
+			}
+		}
+		cursor, err := config.LoadEventsCursor(cursorKey)
+		if err != nil {
+			return errors.Wrap(err, "error loading cursor")
+		}
+		rangeStart := ""
+		if cursor == "" {
+			rangeStart = time.Now().Add(-since).UTC().Format(time.RFC3339)
+			printer.PrintMsg(fmt.Sprintf("Tailing events since %s (press Ctrl+C to stop)", rangeStart))
+		} else {
+			printer.PrintMsg(fmt.Sprintf("Resuming events after %s (press Ctrl+C to stop)", cursor))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		// Other formats stream through a list printer so CSV has a single header and JSON forms one array
+		var p *printer.ListPrinter
+		if printer.IsTable() {
+			fmt.Println(printer.TableHeader(fmt.Sprintf("%-24s  %-36s  %s", "Created At", "Event", "ID")))
+		} else {
+			p = printer.NewListPrinter(0, "ID", "Event", "Created At", "Data")
+			defer p.Flush()
+		}
+		for {
+			response, err := clients.events.ListEvents(
+				ctx,
+				events.ListEventsOpts{
+					Events:         eventTypes,
+					OrganizationId: organization,
+					Limit:          list.MaxPageSize,
+					After:          cursor,
+					RangeStart:     rangeStart,
+				},
+			)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return errors.Wrap(err, "error listing events")
+			}
+
+			for _, event := range response.Data {
+				if printer.IsTable() {
+					fmt.Printf("%-24s  %-36s  %s\n", event.CreatedAt.Format(time.RFC3339), event.Event, event.ID)
+				} else {
+					p.Add(event, event.ID, event.Event, event.CreatedAt.Format(time.RFC3339), string(event.Data))
+				}
+			}
+
+			if len(response.Data) > 0 {
+				cursor = response.Data[len(response.Data)-1].ID
+				// The cursor takes over from the range once events have been seen
+				rangeStart = ""
+				if err := config.SaveEventsCursor(cursorKey, cursor); err != nil {
+					printer.PrintWarning(fmt.Sprintf("unable to save cursor: %v", err))
+				}
+			}
+			// A full page means there are more events waiting, fetch them right away
+			if len(response.Data) == list.MaxPageSize {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+// eventsCursorKey identifies the saved cursor for a tail. Tails with different filters don't
+// share a cursor, since resuming from one would skip events the other filter didn't match.
+func eventsCursorKey(environment string, eventTypes []string, organization string) string {
+	key := environment + " " + strings.Join(slices.Sorted(slices.Values(eventTypes)), ",")
+	if organization != "" {
+		key += " " + organization
+	}
+	return key
+}
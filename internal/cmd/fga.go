@@ -3,33 +3,108 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/charmbracelet/lipgloss/list"
+	"github.com/charmbracelet/huh"
+	lipglossList "github.com/charmbracelet/lipgloss/list"
 
 	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/workos/workos-cli/internal/fgaschema"
+	"github.com/workos/workos-cli/internal/list"
 	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
 	"github.com/workos/workos-go/v4/pkg/fga"
 	"github.com/workos/workos-go/v4/pkg/workos_errors"
+	"gopkg.in/yaml.v3"
 )
 
 var resourceTypesFile string
 
+const (
+	// warrantBatchSize is the maximum number of warrants written per batch request
+	warrantBatchSize = 100
+	// checkBatchSize is the maximum number of checks evaluated per batch request
+	checkBatchSize = 100
+)
+
+// warrantResult is printed by warrant commands when a machine-readable output format is selected
+type warrantResult struct {
+	Op           string      `json:"op"`
+	Warrant      fga.Warrant `json:"warrant"`
+	WarrantToken string      `json:"warrant_token"`
+}
+
+// warrantSpec is a warrant as written in a warrants file
+type warrantSpec struct {
+	Subject  string `json:"subject"          yaml:"subject"`
+	Relation string `json:"relation"         yaml:"relation"`
+	Resource string `json:"resource"         yaml:"resource"`
+	Policy   string `json:"policy,omitempty" yaml:"policy,omitempty"`
+}
+
+// warrantPlan is the set of changes made by warrant apply
+type warrantPlan struct {
+	Create       []fga.Warrant `json:"create"`
+	Delete       []fga.Warrant `json:"delete"`
+	WarrantToken string        `json:"warrant_token,omitempty"`
+}
+
+// checkSpec is a check as written in a batch file
+type checkSpec struct {
+	Subject  string      `json:"subject"           yaml:"subject"`
+	Relation string      `json:"relation"          yaml:"relation"`
+	Resource string      `json:"resource"          yaml:"resource"`
+	Context  fga.Context `json:"context,omitempty" yaml:"context,omitempty"`
+	Expect   *bool       `json:"expect,omitempty"  yaml:"expect,omitempty"`
+}
+
+// checkBatchSummary counts the outcomes of a batch check
+type checkBatchSummary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// schemaDiagnostic is printed by schema lint when a machine-readable output format is selected
+type schemaDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// checkResult is printed by the check command when a machine-readable output format is selected
+type checkResult struct {
+	Check      fga.WarrantCheck `json:"check"`
+	Result     string           `json:"result"`
+	Authorized bool             `json:"authorized"`
+	Assert     *bool            `json:"assert,omitempty"`
+	Passed     *bool            `json:"passed,omitempty"`
+	DebugInfo  any              `json:"debug_info,omitempty"`
+}
+
 func init() {
 	// resource-types
-	listResourceTypesCmd.Flags().Int("limit", 10, "limit the number of results returned")
-	listResourceTypesCmd.Flags().String("before", "", "cursor indicating results that occur before a specific result")
-	listResourceTypesCmd.Flags().String("after", "", "cursor indicating results that occur after a specific result")
-	listResourceTypesCmd.Flags().String("order", "", "order in which a list of results should be returned (asc or desc)")
+	listResourceTypesCmd.Flags().Int(list.FlagLimit, 10, "limit the number of results returned")
+	listResourceTypesCmd.Flags().String(list.FlagBefore, "", "cursor indicating results that occur before a specific result")
+	listResourceTypesCmd.Flags().String(list.FlagAfter, "", "cursor indicating results that occur after a specific result")
+	listResourceTypesCmd.Flags().String(list.FlagOrder, "", "order in which a list of results should be returned (asc or desc)")
+	list.AddAllFlags(listResourceTypesCmd.Flags())
 	resourceTypeCmd.AddCommand(listResourceTypesCmd)
 	applyResourceTypesCmd.Flags().StringVarP(&resourceTypesFile, "file", "f", "", "file containing resource type definitions")
+	addPlanFlags(applyResourceTypesCmd.Flags())
 	resourceTypeCmd.AddCommand(applyResourceTypesCmd)
 	fgaCmd.AddCommand(resourceTypeCmd)
 
@@ -37,22 +112,41 @@ func init() {
 	createWarrantCmd.Flags().StringP("policy", "p", "", "boolean expression to be evaluated for a warrant at the time of a check")
 	warrantCmd.AddCommand(createWarrantCmd)
 	warrantCmd.AddCommand(deleteWarrantCmd)
+	applyWarrantsCmd.Flags().StringP("file", "f", "", "file containing warrant definitions (defaults to stdin)")
+	applyWarrantsCmd.Flags().Bool("prune", false, "delete warrants that are not in the file")
+	addPlanFlags(applyWarrantsCmd.Flags())
+	warrantCmd.AddCommand(applyWarrantsCmd)
+	listWarrantsCmd.Flags().String("resource-type", "", "resource type to filter results by")
+	listWarrantsCmd.Flags().String("resource-id", "", "resource id to filter results by")
+	listWarrantsCmd.Flags().String("relation", "", "relation to filter results by")
+	listWarrantsCmd.Flags().String("subject-type", "", "subject type to filter results by")
+	listWarrantsCmd.Flags().String("subject-id", "", "subject id to filter results by")
+	listWarrantsCmd.Flags().String("subject-relation", "", "subject relation to filter results by")
+	listWarrantsCmd.Flags().StringP("warrantToken", "w", "", "warrant token to use for list")
+	listWarrantsCmd.Flags().Int(list.FlagLimit, 10, "limit the number of results returned")
+	listWarrantsCmd.Flags().String(list.FlagBefore, "", "cursor indicating results that occur before a specific result")
+	listWarrantsCmd.Flags().String(list.FlagAfter, "", "cursor indicating results that occur after a specific result")
+	listWarrantsCmd.Flags().String(list.FlagOrder, "", "order in which a list of results should be returned (asc or desc)")
+	list.AddAllFlags(listWarrantsCmd.Flags())
+	warrantCmd.AddCommand(listWarrantsCmd)
 	fgaCmd.AddCommand(warrantCmd)
 
 	// check
 	checkRelationCmd.Flags().StringP("warrantToken", "w", "", "warrant token to use for check")
 	checkRelationCmd.Flags().String("assert", "", "assert that the check is true or false")
 	checkRelationCmd.Flags().BoolP("debug", "d", false, "run check in debug mode")
+	checkRelationCmd.Flags().String("batch", "", "file of checks to run in batch (JSON, YAML or CSV, - for stdin)")
 	fgaCmd.AddCommand(checkRelationCmd)
 
 	// resources
 	resourceCmd.AddCommand(createResourceCmd)
 	listResourcesCmd.Flags().String("type", "", "resource type to filter results by")
 	listResourcesCmd.Flags().String("search", "", "search term to filter a list of results by")
-	listResourcesCmd.Flags().Int("limit", 10, "limit the number of results returned")
-	listResourcesCmd.Flags().String("before", "", "cursor indicating results that occur before a specific result")
-	listResourcesCmd.Flags().String("after", "", "cursor indicating results that occur after a specific result")
-	listResourcesCmd.Flags().String("order", "", "order in which a list of results should be returned (asc or desc)")
+	listResourcesCmd.Flags().Int(list.FlagLimit, 10, "limit the number of results returned")
+	listResourcesCmd.Flags().String(list.FlagBefore, "", "cursor indicating results that occur before a specific result")
+	listResourcesCmd.Flags().String(list.FlagAfter, "", "cursor indicating results that occur after a specific result")
+	listResourcesCmd.Flags().String(list.FlagOrder, "", "order in which a list of results should be returned (asc or desc)")
+	list.AddAllFlags(listResourcesCmd.Flags())
 	resourceCmd.AddCommand(listResourcesCmd)
 	resourceCmd.AddCommand(updateResourceCmd)
 	resourceCmd.AddCommand(deleteResourceCmd)
@@ -60,21 +154,37 @@ func init() {
 
 	// query
 	queryCmd.Flags().StringP("warrantToken", "w", "", "warrant token to use for query")
-	queryCmd.Flags().Int("limit", 10, "limit the number of results returned")
-	queryCmd.Flags().String("before", "", "cursor indicating results that occur before a specific result")
-	queryCmd.Flags().String("after", "", "cursor indicating results that occur after a specific result")
-	queryCmd.Flags().String("order", "", "order in which a list of results should be returned (asc or desc)")
+	queryCmd.Flags().Int(list.FlagLimit, 10, "limit the number of results returned")
+	queryCmd.Flags().String(list.FlagBefore, "", "cursor indicating results that occur before a specific result")
+	queryCmd.Flags().String(list.FlagAfter, "", "cursor indicating results that occur after a specific result")
+	queryCmd.Flags().String(list.FlagOrder, "", "order in which a list of results should be returned (asc or desc)")
+	list.AddAllFlags(queryCmd.Flags())
 	fgaCmd.AddCommand(queryCmd)
 
 	// schema
 	convertSchemaCMD.Flags().String("to", "json", "output to (schema or json)")
-	convertSchemaCMD.Flags().String("output", "pretty", "output pretty or raw. use raw for machine-readable output or writing to a file")
+	convertSchemaCMD.Flags().Bool("raw", false, "print only the converted schema or JSON, for machine-readable output or writing to a file")
+	// Shadows the global --output flag, which this command used to take pretty or raw for. Those
+	// values are still accepted, and any other value sets the output format like the global flag.
+	convertSchemaCMD.Flags().StringP(FlagOutput, "o", printer.FormatTable, "Output format ("+strings.Join(printer.Formats, ", ")+"). pretty and raw are deprecated, use --raw instead")
 	schemaCmd.AddCommand(convertSchemaCMD)
 	applySchemaCmd.Flags().BoolP("verbose", "v", false, "print extra details about the request")
 	applySchemaCmd.Flags().Bool("strict", false, "fail if there are warnings")
+	addPlanFlags(applySchemaCmd.Flags())
 	schemaCmd.AddCommand(applySchemaCmd)
+	schemaCmd.AddCommand(diffSchemaCmd)
+	exportSchemaCmd.Flags().StringP("file", "f", "", "file to write the schema to (defaults to stdout)")
+	exportSchemaCmd.Flags().String("format", "schema", "format to export (schema or json)")
+	exportSchemaCmd.Flags().String("schema-version", "0.3", "schema language version to export")
+	schemaCmd.AddCommand(exportSchemaCmd)
+	fmtSchemaCmd.Flags().Bool("check", false, "list files that aren't formatted and exit non-zero if there are any")
+	fmtSchemaCmd.Flags().BoolP("write", "w", false, "write the formatted schema back to the file")
+	schemaCmd.AddCommand(fmtSchemaCmd)
+	lintSchemaCmd.Flags().Bool("strict", false, "exit non-zero on warnings as well as errors")
+	schemaCmd.AddCommand(lintSchemaCmd)
 	fgaCmd.AddCommand(schemaCmd)
 
+	guard(applyResourceTypesCmd, createWarrantCmd, deleteWarrantCmd, applyWarrantsCmd, createResourceCmd, updateResourceCmd, deleteResourceCmd, applySchemaCmd)
 	rootCmd.AddCommand(fgaCmd)
 }
 
@@ -93,40 +203,65 @@ var resourceTypeCmd = &cobra.Command{
 var listResourceTypesCmd = &cobra.Command{
 	Use:     "list",
 	Short:   "List resource types",
-	Long:    "List resource types, optionally providing common flags to filter and paginate the results.",
+	Long:    "List resource types, optionally providing common flags to filter and paginate the results or '--all' to fetch every page.",
 	Example: "workos fga resourcetype list --limit=5",
 	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		resourceTypes, err := fga.ListResourceTypes(context.Background(), fga.ListResourceTypesOpts{
-			Limit: 100,
-		})
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
 		if err != nil {
-			return errors.Errorf("error listing resource types: %v", err)
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.Errorf("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.Errorf("invalid order flag")
 		}
 
-		tbl := printer.NewTable(80).Headers(
-			printer.TableHeader("Resource Type"),
+		p := printer.NewListPrinter(80, "Resource Type")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]fga.ResourceType, common.ListMetadata, error) {
+				resourceTypes, err := clients.fga.ListResourceTypes(context.Background(), fga.ListResourceTypesOpts{
+					Limit:  limit,
+					Before: before,
+					After:  after,
+					Order:  fgaOrder(order),
+				})
+				return resourceTypes.Data, resourceTypes.ListMetadata, err
+			},
+			func(resourceType fga.ResourceType) error {
+				p.Add(
+					resourceType,
+					resourceType.Type,
+				)
+				return nil
+			},
 		)
-		for _, resourceType := range resourceTypes.Data {
-			tbl.Row(
-				resourceType.Type,
-			)
+		if err != nil {
+			return errors.Errorf("error listing resource types: %v", err)
 		}
 
-		printer.PrintMsg(tbl.Render())
-		printer.PrintMsg(fmt.Sprintf("Before: %s", resourceTypes.ListMetadata.Before))
-		printer.PrintMsg(fmt.Sprintf("After: %s", resourceTypes.ListMetadata.After))
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
 		return nil
 	},
 }
 
 var applyResourceTypesCmd = &cobra.Command{
-	Use:     "apply",
-	Short:   "Apply a set of resource types",
-	Long:    "Apply a set of resource types from a specified file. This command will create any resource types present in the file and delete any resource types that are not.",
+	Use:   "apply",
+	Short: "Apply a set of resource types",
+	Long: "Apply a set of resource types from a specified file. This command will create any resource types present in the file and delete any resource types that are not. " +
+		"The changes are printed first and removing types or relations asks for confirmation. Use --dry-run to only print the changes.",
 	Example: "workos fga resourcetype apply -f resource-types.json",
 	Args:    cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		var bytes []byte
 		var err error
 		if resourceTypesFile != "" {
@@ -153,12 +288,17 @@ var applyResourceTypesCmd = &cobra.Command{
 			return err
 		}
 
-		_, err = fga.BatchUpdateResourceTypes(context.Background(), resourceTypes)
+		proceed, err := planResourceTypeChanges(cmd, clients.fga, resourceTypes)
+		if err != nil || !proceed {
+			return err
+		}
+
+		updatedResourceTypes, err := clients.fga.BatchUpdateResourceTypes(context.Background(), resourceTypes)
 		if err != nil {
 			return err
 		}
 
-		printer.PrintMsg("Resource types updated")
+		printer.PrintResult("Resource types updated", updatedResourceTypes)
 		return nil
 	},
 }
@@ -166,7 +306,7 @@ var applyResourceTypesCmd = &cobra.Command{
 var warrantCmd = &cobra.Command{
 	Use:   "warrant",
 	Short: "Manage your warrants",
-	Long:  "Create and delete warrants that define the relationships between resources in your application.",
+	Long:  "List, create, delete and apply warrants that define the relationships between resources in your application.",
 }
 
 var createWarrantCmd = &cobra.Command{
@@ -176,15 +316,15 @@ var createWarrantCmd = &cobra.Command{
 	Example: "workos fga warrant create user:john owner document:xyz --policy \"region == 'eu'\"",
 	Args:    cobra.ExactArgs(3),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		subjectType, subjectIdRelation, valid := strings.Cut(args[0], ":")
-		if !valid {
-			return errors.Errorf("invalid subject: %s", args[0])
+		clients := newClients()
+		subject, err := parseSubject(args[0])
+		if err != nil {
+			return err
 		}
-		subjectId, subjectRelation, _ := strings.Cut(subjectIdRelation, "#")
 		relation := args[1]
-		resourceType, resourceId, valid := strings.Cut(args[2], ":")
-		if !valid {
-			return errors.Errorf("invalid resource: %s", args[0])
+		resourceType, resourceId, err := parseResource(args[2])
+		if err != nil {
+			return err
 		}
 
 		policy, err := cmd.Flags().GetString("policy")
@@ -192,31 +332,36 @@ var createWarrantCmd = &cobra.Command{
 			return errors.Errorf("invalid policy flag")
 		}
 
-		res, err := fga.WriteWarrant(
+		warrant := fga.Warrant{
+			ResourceType: resourceType,
+			ResourceId:   resourceId,
+			Relation:     relation,
+			Subject:      subject,
+			Policy:       policy,
+		}
+		res, err := clients.fga.WriteWarrant(
 			context.Background(),
 			fga.WriteWarrantOpts{
 				Op:           fga.WarrantOpCreate,
-				ResourceType: resourceType,
-				ResourceId:   resourceId,
-				Relation:     relation,
-				Subject: fga.Subject{
-					ResourceType: subjectType,
-					ResourceId:   subjectId,
-					Relation:     subjectRelation,
-				},
-				Policy: policy,
+				ResourceType: warrant.ResourceType,
+				ResourceId:   warrant.ResourceId,
+				Relation:     warrant.Relation,
+				Subject:      warrant.Subject,
+				Policy:       warrant.Policy,
 			},
 		)
 		if err != nil {
 			return errors.Errorf("error creating warrant: %v", err)
 		}
 
+		msg := fmt.Sprintf("Assigned %s %s %s", args[0], args[1], args[2])
 		if policy != "" {
-			printer.PrintMsg(fmt.Sprintf("Assigned %s %s %s [%s]", args[0], args[1], args[2], policy))
-		} else {
-			printer.PrintMsg(fmt.Sprintf("Assigned %s %s %s", args[0], args[1], args[2]))
+			msg = fmt.Sprintf("%s [%s]", msg, policy)
 		}
-		printer.PrintMsg(fmt.Sprintf("Warrant-Token: %s", res.WarrantToken))
+		printer.PrintResult(
+			fmt.Sprintf("%s\nWarrant-Token: %s", msg, res.WarrantToken),
+			warrantResult{Op: fga.WarrantOpCreate, Warrant: warrant, WarrantToken: res.WarrantToken},
+		)
 		return nil
 	},
 }
@@ -228,37 +373,262 @@ var deleteWarrantCmd = &cobra.Command{
 	Example: "workos fga warrant delete user:john owner document:xyz",
 	Args:    cobra.ExactArgs(3),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		subjectType, subjectIdRelation, valid := strings.Cut(args[0], ":")
-		if !valid {
-			return errors.Errorf("invalid subject: %s", args[0])
+		clients := newClients()
+		subject, err := parseSubject(args[0])
+		if err != nil {
+			return err
 		}
-		subjectId, subjectRelation, _ := strings.Cut(subjectIdRelation, "#")
 		relation := args[1]
-		resourceType, resourceId, valid := strings.Cut(args[2], ":")
-		if !valid {
-			return errors.Errorf("invalid resource: %s", args[0])
+		resourceType, resourceId, err := parseResource(args[2])
+		if err != nil {
+			return err
 		}
 
-		res, err := fga.WriteWarrant(
+		warrant := fga.Warrant{
+			ResourceType: resourceType,
+			ResourceId:   resourceId,
+			Relation:     relation,
+			Subject:      subject,
+		}
+		res, err := clients.fga.WriteWarrant(
 			context.Background(),
 			fga.WriteWarrantOpts{
 				Op:           fga.WarrantOpDelete,
-				ResourceType: resourceType,
-				ResourceId:   resourceId,
-				Relation:     relation,
-				Subject: fga.Subject{
-					ResourceType: subjectType,
-					ResourceId:   subjectId,
-					Relation:     subjectRelation,
-				},
+				ResourceType: warrant.ResourceType,
+				ResourceId:   warrant.ResourceId,
+				Relation:     warrant.Relation,
+				Subject:      warrant.Subject,
 			},
 		)
 		if err != nil {
 			return errors.Errorf("error removing relation: %v", err)
 		}
 
-		printer.PrintMsg(fmt.Sprintf("Removed %s %s %s", args[0], args[1], args[2]))
-		printer.PrintMsg(fmt.Sprintf("Warrant-Token: %s", res.WarrantToken))
+		printer.PrintResult(
+			fmt.Sprintf("Removed %s %s %s\nWarrant-Token: %s", args[0], args[1], args[2], res.WarrantToken),
+			warrantResult{Op: fga.WarrantOpDelete, Warrant: warrant, WarrantToken: res.WarrantToken},
+		)
+		return nil
+	},
+}
+
+var listWarrantsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List warrants",
+	Long:    "List warrants, optionally filtering by resource, relation or subject and providing common flags to paginate the results or '--all' to fetch every page.",
+	Example: "workos fga warrant list --resource-type document --subject-type user --subject-id john",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		resourceType, err := cmd.Flags().GetString("resource-type")
+		if err != nil {
+			return errors.New("invalid resource-type flag")
+		}
+		resourceId, err := cmd.Flags().GetString("resource-id")
+		if err != nil {
+			return errors.New("invalid resource-id flag")
+		}
+		relation, err := cmd.Flags().GetString("relation")
+		if err != nil {
+			return errors.New("invalid relation flag")
+		}
+		subjectType, err := cmd.Flags().GetString("subject-type")
+		if err != nil {
+			return errors.New("invalid subject-type flag")
+		}
+		subjectId, err := cmd.Flags().GetString("subject-id")
+		if err != nil {
+			return errors.New("invalid subject-id flag")
+		}
+		subjectRelation, err := cmd.Flags().GetString("subject-relation")
+		if err != nil {
+			return errors.New("invalid subject-relation flag")
+		}
+		warrantToken, err := cmd.Flags().GetString("warrantToken")
+		if err != nil {
+			return errors.New("invalid warrantToken flag")
+		}
+
+		p := printer.NewListPrinter(120, "Subject", "Relation", "Resource", "Policy")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]fga.Warrant, common.ListMetadata, error) {
+				warrants, err := clients.fga.ListWarrants(context.Background(), fga.ListWarrantsOpts{
+					ResourceType:    resourceType,
+					ResourceId:      resourceId,
+					Relation:        relation,
+					SubjectType:     subjectType,
+					SubjectId:       subjectId,
+					SubjectRelation: subjectRelation,
+					Limit:           limit,
+					Before:          before,
+					After:           after,
+					Order:           fgaOrder(order),
+					WarrantToken:    warrantToken,
+				})
+				return warrants.Data, warrants.ListMetadata, err
+			},
+			func(warrant fga.Warrant) error {
+				p.Add(
+					warrant,
+					subjectAsString(warrant.Subject),
+					warrant.Relation,
+					fmt.Sprintf("%s:%s", warrant.ResourceType, warrant.ResourceId),
+					warrant.Policy,
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Errorf("error listing warrants: %v", err)
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var applyWarrantsCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a set of warrants",
+	Long: "Apply a set of warrants from a YAML or JSON file. Warrants in the file that don't exist yet are created and, with --prune, " +
+		"every existing warrant in the environment that isn't in the file is deleted. The plan is printed before it's applied, and deletions need confirmation unless --yes is set.",
+	Example: `workos fga warrant apply -f warrants.yaml --prune
+
+# warrants.yaml
+- subject: user:john
+  relation: owner
+  resource: document:xyz
+- subject: role:admin#member
+  relation: editor
+  resource: document:xyz
+  policy: "region == 'eu'"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return errors.New("invalid file flag")
+		}
+		prune, err := cmd.Flags().GetBool("prune")
+		if err != nil {
+			return errors.New("invalid prune flag")
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return errors.New("invalid dry-run flag")
+		}
+		yes, err := cmd.Flags().GetBool(FlagYes)
+		if err != nil {
+			return errors.New("invalid yes flag")
+		}
+
+		bytes, err := readFileOrStdin(file)
+		if err != nil {
+			return err
+		}
+		var specs []warrantSpec
+		err = yaml.Unmarshal(bytes, &specs)
+		if err != nil {
+			return errors.Errorf("invalid warrants file: %v", err)
+		}
+		desired := make(map[string]fga.Warrant, len(specs))
+		for i, spec := range specs {
+			warrant, err := spec.warrant()
+			if err != nil {
+				return errors.Errorf("invalid warrant at index %d: %v", i, err)
+			}
+			desired[warrantAsString(warrant)] = warrant
+		}
+
+		current, err := listAllWarrants(clients.fga, fga.ListWarrantsOpts{})
+		if err != nil {
+			return errors.Errorf("error listing warrants: %v", err)
+		}
+		existing := make(map[string]fga.Warrant, len(current))
+		for _, warrant := range current {
+			existing[warrantAsString(warrant)] = warrant
+		}
+
+		plan := warrantPlan{Create: []fga.Warrant{}, Delete: []fga.Warrant{}}
+		for key, warrant := range desired {
+			if _, ok := existing[key]; !ok {
+				plan.Create = append(plan.Create, warrant)
+			}
+		}
+		if prune {
+			for key, warrant := range existing {
+				if _, ok := desired[key]; !ok {
+					plan.Delete = append(plan.Delete, warrant)
+				}
+			}
+		}
+		sortWarrants(plan.Create)
+		sortWarrants(plan.Delete)
+
+		if printer.IsTable() {
+			for _, warrant := range plan.Create {
+				printer.PrintMsg(printer.GreenText("+ " + warrantAsString(warrant)))
+			}
+			for _, warrant := range plan.Delete {
+				printer.PrintMsg(printer.RedText("- " + warrantAsString(warrant)))
+			}
+			printer.PrintMsg(fmt.Sprintf("Plan: %d to create, %d to delete", len(plan.Create), len(plan.Delete)))
+		}
+		if len(plan.Create) == 0 && len(plan.Delete) == 0 {
+			printer.PrintResult("Warrants are up to date", plan)
+			return nil
+		}
+		if dryRun {
+			printer.PrintResult("Dry run, no changes applied", plan)
+			return nil
+		}
+		if len(plan.Delete) > 0 && !yes {
+			confirmed, err := confirmChanges(fmt.Sprintf("This deletes %d warrants that aren't in the file. Apply?", len(plan.Delete)))
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return errors.New("apply cancelled")
+			}
+		}
+
+		// Create before deleting so that replaced warrants never leave a gap in access
+		var writes []fga.WriteWarrantOpts
+		for _, warrant := range plan.Create {
+			writes = append(writes, warrantWriteOpts(fga.WarrantOpCreate, warrant))
+		}
+		for _, warrant := range plan.Delete {
+			writes = append(writes, warrantWriteOpts(fga.WarrantOpDelete, warrant))
+		}
+		for start := 0; start < len(writes); start += warrantBatchSize {
+			end := min(start+warrantBatchSize, len(writes))
+			res, err := clients.fga.BatchWriteWarrants(context.Background(), writes[start:end])
+			if err != nil {
+				return errors.Errorf("error applying warrants (%d of %d applied): %v", start, len(writes), err)
+			}
+			plan.WarrantToken = res.WarrantToken
+		}
+
+		printer.PrintResult(
+			fmt.Sprintf("Applied %d warrant changes\nWarrant-Token: %s", len(writes), plan.WarrantToken),
+			plan,
+		)
 		return nil
 	},
 }
@@ -276,6 +646,7 @@ var createResourceCmd = &cobra.Command{
 	Example: `workos fga resource create user:john '{"email":"john.doe@workos.com"}'`,
 	Args:    cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		resourceType, resourceId, valid := strings.Cut(args[0], ":")
 		if !valid {
 			return errors.Errorf("invalid resource: %s", args[0])
@@ -290,7 +661,7 @@ var createResourceCmd = &cobra.Command{
 			}
 		}
 
-		createdResource, err := fga.CreateResource(context.Background(), fga.CreateResourceOpts{
+		createdResource, err := clients.fga.CreateResource(context.Background(), fga.CreateResourceOpts{
 			ResourceType: resourceType,
 			ResourceId:   resourceId,
 			Meta:         meta,
@@ -300,9 +671,9 @@ var createResourceCmd = &cobra.Command{
 		}
 
 		if len(createdResource.Meta) > 0 {
-			printer.PrintMsg(fmt.Sprintf("Created resource %s:%s (%v)", createdResource.ResourceType, createdResource.ResourceId, createdResource.Meta))
+			printer.PrintResult(fmt.Sprintf("Created resource %s:%s (%v)", createdResource.ResourceType, createdResource.ResourceId, createdResource.Meta), createdResource)
 		} else {
-			printer.PrintMsg(fmt.Sprintf("Created resource %s:%s", createdResource.ResourceType, createdResource.ResourceId))
+			printer.PrintResult(fmt.Sprintf("Created resource %s:%s", createdResource.ResourceType, createdResource.ResourceId), createdResource)
 		}
 
 		return nil
@@ -310,12 +681,14 @@ var createResourceCmd = &cobra.Command{
 }
 
 var listResourcesCmd = &cobra.Command{
-	Use:     "list",
-	Short:   "List resources",
-	Long:    "List resources, optionally specifying the '--type' flag to filter to resources of a specific type or providing common flags to filter and paginate the results.",
-	Example: "workos fga resource list --type=user --limit=15",
-	Args:    cobra.NoArgs,
+	Use:   "list",
+	Short: "List resources",
+	Long:  "List resources, optionally specifying the '--type' flag to filter to resources of a specific type or providing common flags to filter and paginate the results. Use '--all' to fetch every page.",
+	Example: `workos fga resource list --type=user --limit=15
+workos fga resource list --type=user --all -o ndjson`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		resourceType, err := cmd.Flags().GetString("type")
 		if err != nil {
 			return errors.Errorf("invalid type flag")
@@ -324,63 +697,55 @@ var listResourcesCmd = &cobra.Command{
 		if err != nil {
 			return errors.Errorf("invalid search flag")
 		}
-		limit, err := cmd.Flags().GetInt("limit")
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
 		if err != nil {
-			return errors.Errorf("invalid limit flag")
+			return err
 		}
-		before, err := cmd.Flags().GetString("before")
+		before, err := cmd.Flags().GetString(list.FlagBefore)
 		if err != nil {
 			return errors.Errorf("invalid before flag")
 		}
-		after, err := cmd.Flags().GetString("after")
-		if err != nil {
-			return errors.Errorf("invalid after flag")
-		}
-		order, err := cmd.Flags().GetString("order")
+		order, err := cmd.Flags().GetString(list.FlagOrder)
 		if err != nil {
 			return errors.Errorf("invalid order flag")
 		}
-		var orderFilter fga.Order
-		if order != "" {
-			if strings.ToLower(order) == "asc" {
-				orderFilter = fga.Asc
-			} else {
-				orderFilter = fga.Desc
-			}
-		}
 
-		resources, err := fga.ListResources(context.Background(), fga.ListResourcesOpts{
-			ResourceType: resourceType,
-			Search:       search,
-			Limit:        limit,
-			Before:       before,
-			After:        after,
-			Order:        orderFilter,
-		})
+		p := printer.NewListPrinter(120, "Resource Type", "Resource ID", "Meta")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]fga.Resource, common.ListMetadata, error) {
+				resources, err := clients.fga.ListResources(context.Background(), fga.ListResourcesOpts{
+					ResourceType: resourceType,
+					Search:       search,
+					Limit:        limit,
+					Before:       before,
+					After:        after,
+					Order:        fgaOrder(order),
+				})
+				return resources.Data, resources.ListMetadata, err
+			},
+			func(resource fga.Resource) error {
+				metaString, err := json.MarshalIndent(resource.Meta, "", "    ")
+				if err != nil {
+					return err
+				}
+				p.Add(
+					resource,
+					resource.ResourceType,
+					resource.ResourceId,
+					string(metaString),
+				)
+				return nil
+			},
+		)
 		if err != nil {
 			return errors.Errorf("error listing resources: %v", err)
 		}
 
-		tbl := printer.NewTable(120).Headers(
-			printer.TableHeader("Resource Type"),
-			printer.TableHeader("Resource ID"),
-			printer.TableHeader("Meta"),
-		)
-		for _, resource := range resources.Data {
-			metaString, err := json.MarshalIndent(resource.Meta, "", "    ")
-			if err != nil {
-				return errors.Errorf("error listing resources: %v", err)
-			}
-			tbl.Row(
-				resource.ResourceType,
-				resource.ResourceId,
-				string(metaString),
-			)
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
 		}
-
-		printer.PrintMsg(tbl.Render())
-		printer.PrintMsg(fmt.Sprintf("Before: %s", resources.ListMetadata.Before))
-		printer.PrintMsg(fmt.Sprintf("After: %s", resources.ListMetadata.After))
+		p.Flush()
 		return nil
 	},
 }
@@ -392,6 +757,7 @@ var updateResourceCmd = &cobra.Command{
 	Example: `workos fga resource update user:john '{"email":"john.doe@workos.com"}'`,
 	Args:    cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		resourceType, resourceId, valid := strings.Cut(args[0], ":")
 		if !valid {
 			return errors.Errorf("invalid resource: %s", args[0])
@@ -403,7 +769,7 @@ var updateResourceCmd = &cobra.Command{
 			return errors.Errorf("invalid meta: %s", args[1])
 		}
 
-		updatedResource, err := fga.UpdateResource(context.Background(), fga.UpdateResourceOpts{
+		updatedResource, err := clients.fga.UpdateResource(context.Background(), fga.UpdateResourceOpts{
 			ResourceType: resourceType,
 			ResourceId:   resourceId,
 			Meta:         meta,
@@ -412,7 +778,7 @@ var updateResourceCmd = &cobra.Command{
 			return errors.Errorf("error updating resource: %v", err)
 		}
 
-		printer.PrintMsg(fmt.Sprintf("Updated resource %s:%s", updatedResource.ResourceType, updatedResource.ResourceId))
+		printer.PrintResult(fmt.Sprintf("Updated resource %s:%s", updatedResource.ResourceType, updatedResource.ResourceId), updatedResource)
 		return nil
 	},
 }
@@ -424,12 +790,13 @@ var deleteResourceCmd = &cobra.Command{
 	Example: `workos fga resource delete user:john`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		resourceType, resourceId, valid := strings.Cut(args[0], ":")
 		if !valid {
 			return errors.Errorf("invalid resource: %s", args[0])
 		}
 
-		err := fga.DeleteResource(context.Background(), fga.DeleteResourceOpts{
+		err := clients.fga.DeleteResource(context.Background(), fga.DeleteResourceOpts{
 			ResourceType: resourceType,
 			ResourceId:   resourceId,
 		})
@@ -437,27 +804,61 @@ var deleteResourceCmd = &cobra.Command{
 			return errors.Errorf("error deleting resource: %v", err)
 		}
 
-		printer.PrintMsg(fmt.Sprintf("Deleted resource %s", args[0]))
+		printer.PrintResult(fmt.Sprintf("Deleted resource %s", args[0]), deletedResult{ID: args[0], Deleted: true})
 		return nil
 	},
 }
 
 var checkRelationCmd = &cobra.Command{
-	Use:     "check <subject> <relation> <resource> [context]",
-	Short:   "Check for a relation",
-	Long:    "Check if a given subject has the specified relation on a given resource, optionally specifying context to use while evaluating the check.",
-	Example: `workos fga check user:john owner document:xyz '{"organization": "acme"}'`,
-	Args:    cobra.RangeArgs(3, 4),
+	Use:   "check <subject> <relation> <resource> [context]",
+	Short: "Check for a relation",
+	Long: "Check if a given subject has the specified relation on a given resource, optionally specifying context to use while evaluating the check. " +
+		"Use --batch to run many checks from a JSON, YAML or CSV file, each with an optional expected result. " +
+		"The command exits non-zero if any expectation fails.",
+	Example: `workos fga check user:john owner document:xyz '{"organization": "acme"}'
+workos fga check --batch checks.yaml
+
+# checks.yaml
+- subject: user:john
+  relation: owner
+  resource: document:xyz
+  context: {"organization": "acme"}
+  expect: true
+
+# checks.csv
+subject,relation,resource,context,expect
+user:john,owner,document:xyz,"{""organization"": ""acme""}",true`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("batch") {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.RangeArgs(3, 4)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		subjectType, subjectIdRelation, valid := strings.Cut(args[0], ":")
-		if !valid {
-			return errors.Errorf("invalid subject: %s", args[0])
+		clients := newClients()
+		if cmd.Flags().Changed("batch") {
+			batchFile, err := cmd.Flags().GetString("batch")
+			if err != nil {
+				return errors.New("invalid batch flag")
+			}
+			if cmd.Flags().Changed("assert") || cmd.Flags().Changed("debug") {
+				return errors.New("--assert and --debug can't be used with --batch, set expect on each check instead")
+			}
+			warrantToken, err := cmd.Flags().GetString("warrantToken")
+			if err != nil {
+				return errors.New("invalid warrantToken flag")
+			}
+			return runCheckBatch(clients.fga, batchFile, warrantToken)
+		}
+
+		subject, err := parseSubject(args[0])
+		if err != nil {
+			return err
 		}
-		subjectId, subjectRelation, _ := strings.Cut(subjectIdRelation, "#")
 		relation := args[1]
-		resourceType, resourceId, valid := strings.Cut(args[2], ":")
-		if !valid {
-			return errors.Errorf("invalid resource: %s", args[0])
+		resourceType, resourceId, err := parseResource(args[2])
+		if err != nil {
+			return err
 		}
 
 		var policyContext map[string]interface{}
@@ -481,14 +882,10 @@ var checkRelationCmd = &cobra.Command{
 			ResourceType: resourceType,
 			ResourceId:   resourceId,
 			Relation:     relation,
-			Subject: fga.Subject{
-				ResourceType: subjectType,
-				ResourceId:   subjectId,
-				Relation:     subjectRelation,
-			},
-			Context: policyContext,
+			Subject:      subject,
+			Context:      policyContext,
 		}
-		result, err := fga.Check(
+		result, err := clients.fga.Check(
 			context.Background(),
 			fga.CheckOpts{
 				Checks: []fga.WarrantCheck{
@@ -511,6 +908,25 @@ var checkRelationCmd = &cobra.Command{
 		if err != nil {
 			return errors.Wrap(err, "invalid assert flag")
 		}
+		if !printer.IsTable() {
+			var expect *bool
+			if assert != "" {
+				assertBool, err := strconv.ParseBool(assert)
+				if err != nil {
+					return errors.Errorf("invalid assertion: %s", assert)
+				}
+				expect = &assertBool
+			}
+			res := newCheckResult(warrantCheck, result, expect)
+			if debug {
+				res.DebugInfo = result.DebugInfo
+			}
+			printer.Print(res)
+			if res.Passed != nil && !*res.Passed {
+				os.Exit(1)
+			}
+			return nil
+		}
 		if assert != "" {
 			assertBool, err := strconv.ParseBool(assert)
 			if err != nil {
@@ -547,19 +963,16 @@ var queryCmd = &cobra.Command{
 	Example: "workos fga query select document where user:john is owner",
 	Args:    cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		after, err := cmd.Flags().GetString("after")
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
 		if err != nil {
-			return errors.Wrap(err, "invalid after flag")
+			return err
 		}
-		before, err := cmd.Flags().GetString("before")
+		before, err := cmd.Flags().GetString(list.FlagBefore)
 		if err != nil {
 			return errors.Wrap(err, "invalid before flag")
 		}
-		limit, err := cmd.Flags().GetInt("limit")
-		if err != nil {
-			return errors.Wrap(err, "invalid limit flag")
-		}
-		order, err := cmd.Flags().GetString("order")
+		order, err := cmd.Flags().GetString(list.FlagOrder)
 		if err != nil {
 			return errors.Wrap(err, "invalid order flag")
 		}
@@ -575,43 +988,45 @@ var queryCmd = &cobra.Command{
 			}
 		}
 
-		result, err := fga.Query(context.Background(), fga.QueryOpts{
-			Query:        args[0],
-			Context:      policyContext,
-			Limit:        limit,
-			Before:       before,
-			After:        after,
-			Order:        fga.Order(order),
-			WarrantToken: warrantToken,
-		})
+		p := printer.NewListPrinter(120, "Resource Type", "Resource ID", "Relation", "Implicit", "Meta")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]fga.QueryResult, common.ListMetadata, error) {
+				result, err := clients.fga.Query(context.Background(), fga.QueryOpts{
+					Query:        args[0],
+					Context:      policyContext,
+					Limit:        limit,
+					Before:       before,
+					After:        after,
+					Order:        fga.Order(order),
+					WarrantToken: warrantToken,
+				})
+				return result.Data, result.ListMetadata, err
+			},
+			func(queryResult fga.QueryResult) error {
+				metaString, err := json.MarshalIndent(queryResult.Meta, "", "    ")
+				if err != nil {
+					return err
+				}
+				p.Add(
+					queryResult,
+					queryResult.ResourceType,
+					queryResult.ResourceId,
+					queryResult.Relation,
+					strconv.FormatBool(queryResult.IsImplicit),
+					string(metaString),
+				)
+				return nil
+			},
+		)
 		if err != nil {
 			return errors.Errorf("error performing query: %v", err)
 		}
 
-		tbl := printer.NewTable(120).Headers(
-			printer.TableHeader("Resource Type"),
-			printer.TableHeader("Resource ID"),
-			printer.TableHeader("Relation"),
-			printer.TableHeader("Implicit"),
-			printer.TableHeader("Meta"),
-		)
-		for _, queryResult := range result.Data {
-			metaString, err := json.MarshalIndent(queryResult.Meta, "", "    ")
-			if err != nil {
-				return errors.Errorf("error listing resources: %v", err)
-			}
-			tbl.Row(
-				queryResult.ResourceType,
-				queryResult.ResourceId,
-				queryResult.Relation,
-				strconv.FormatBool(queryResult.IsImplicit),
-				string(metaString),
-			)
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
 		}
-
-		printer.PrintMsg(tbl.Render())
-		printer.PrintMsg(fmt.Sprintf("Before: %s", result.ListMetadata.Before))
-		printer.PrintMsg(fmt.Sprintf("After: %s", result.ListMetadata.After))
+		p.Flush()
 		return nil
 	},
 }
@@ -626,17 +1041,32 @@ var convertSchemaCMD = &cobra.Command{
 	Use:     "convert <input_file>",
 	Short:   "Convert a schema to a JSON representation (or JSON to schema)",
 	Long:    "Convert a schema to a JSON representation (or JSON to schema) that can be used to apply resource types.",
-	Example: `workos fga schema convert schema.txt -o json`,
+	Example: `workos fga schema convert schema.txt --to json --raw`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		to, err := cmd.Flags().GetString("to")
 		if err != nil {
 			return errors.Wrap(err, "invalid to flag")
 		}
-		output, err := cmd.Flags().GetString("output")
+		raw, err := cmd.Flags().GetBool("raw")
+		if err != nil {
+			return errors.Wrap(err, "invalid raw flag")
+		}
+		output, err := cmd.Flags().GetString(FlagOutput)
 		if err != nil {
 			return errors.Wrap(err, "invalid output flag")
 		}
+		switch output {
+		case "raw", "pretty":
+			printer.PrintWarning(fmt.Sprintf("--output %s is deprecated, use --raw for raw output", output))
+			raw = output == "raw"
+		default:
+			err = printer.SetFormat(output)
+			if err != nil {
+				return err
+			}
+		}
 
 		bytes, err := os.ReadFile(args[0])
 		if err != nil {
@@ -647,7 +1077,7 @@ var convertSchemaCMD = &cobra.Command{
 		switch to {
 		case "json":
 			schemaString := string(bytes)
-			response, err = fga.ConvertSchemaToResourceTypes(context.Background(), fga.ConvertSchemaToResourceTypesOpts{
+			response, err = clients.fga.ConvertSchemaToResourceTypes(context.Background(), fga.ConvertSchemaToResourceTypesOpts{
 				Schema: schemaString,
 			})
 			if err != nil {
@@ -659,7 +1089,7 @@ var convertSchemaCMD = &cobra.Command{
 			if err != nil {
 				return errors.Errorf("error unmarshalling resource types: %v", err)
 			}
-			response, err = fga.ConvertResourceTypesToSchema(context.Background(), resourceTypesWithVersion)
+			response, err = clients.fga.ConvertResourceTypesToSchema(context.Background(), resourceTypesWithVersion)
 			if err != nil {
 				return convertSchemaError(err)
 			}
@@ -667,8 +1097,14 @@ var convertSchemaCMD = &cobra.Command{
 			return errors.Errorf("invalid conversion: %s", to)
 		}
 
-		switch output {
-		case "pretty":
+		switch {
+		case raw && response.Schema != nil:
+			fmt.Println(*response.Schema)
+		case raw:
+			printer.PrintJson(response.ResourceTypes)
+		case !printer.IsTable():
+			printer.Print(response)
+		default:
 			printer.PrintMsg("Version:")
 			printer.PrintMsg(fmt.Sprintf("%s\n", response.Version))
 
@@ -689,26 +1125,20 @@ var convertSchemaCMD = &cobra.Command{
 				printer.PrintMsg("Resource Types:")
 				printer.PrintJson(response.ResourceTypes)
 			}
-		case "raw":
-			if response.Schema != nil {
-				printer.PrintMsg(*response.Schema)
-			} else {
-				printer.PrintJson(response.ResourceTypes)
-			}
-		default:
-			return errors.Errorf("invalid output: %s", output)
 		}
 		return nil
 	},
 }
 
 var applySchemaCmd = &cobra.Command{
-	Use:     "apply <input_file>",
-	Short:   "Apply a schema",
-	Long:    "Apply a schema to create or update resource types.",
-	Example: `workos fga schema apply schema.txt`,
+	Use:   "apply <input_file>",
+	Short: "Apply a schema",
+	Long: "Apply a schema to create or update resource types. Resource types that aren't in the schema are deleted. " +
+		"The changes are printed first and removing types or relations asks for confirmation. Use --dry-run to only print the changes.",
+	Example: `workos fga schema apply schema.txt --dry-run`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		verbose, err := cmd.Flags().GetBool("verbose")
 		if err != nil {
 			return errors.Wrap(err, "invalid verbose flag")
@@ -723,7 +1153,7 @@ var applySchemaCmd = &cobra.Command{
 			return errors.Errorf("error reading input file: %v", err)
 		}
 		// Convert schema to resource types
-		response, err := fga.ConvertSchemaToResourceTypes(context.Background(), fga.ConvertSchemaToResourceTypesOpts{
+		response, err := clients.fga.ConvertSchemaToResourceTypes(context.Background(), fga.ConvertSchemaToResourceTypesOpts{
 			Schema: string(bytes),
 		})
 		if err != nil {
@@ -741,9 +1171,7 @@ var applySchemaCmd = &cobra.Command{
 			}
 		}
 
-		printer.PrintMsg("applying schema...")
-
-		if verbose {
+		if verbose && printer.IsTable() {
 			printer.PrintJson(response.ResourceTypes)
 		}
 
@@ -752,16 +1180,474 @@ var applySchemaCmd = &cobra.Command{
 			ops = append(ops, fga.UpdateResourceTypeOpts(rt))
 		}
 
-		_, err = fga.BatchUpdateResourceTypes(context.Background(), ops)
+		proceed, err := planResourceTypeChanges(cmd, clients.fga, ops)
+		if err != nil || !proceed {
+			return err
+		}
+		printer.PrintMsg("applying schema...")
+
+		appliedResourceTypes, err := clients.fga.BatchUpdateResourceTypes(context.Background(), ops)
 		if err != nil {
 			return errors.Errorf("error applying schema: %v", err)
 		}
 
-		printer.PrintMsg("Schema applied")
+		printer.PrintResult("Schema applied", appliedResourceTypes)
 		return nil
 	},
 }
 
+var fmtSchemaCmd = &cobra.Command{
+	Use:   "fmt <input_file>...",
+	Short: "Format a schema",
+	Long: "Format schemas in canonical form without calling the API. The formatted schema is printed unless --write is set. " +
+		"Use --check in CI to list unformatted files and exit non-zero if there are any.",
+	Example: `workos fga schema fmt schema.txt --write
+workos fga schema fmt --check schema.txt`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		check, err := cmd.Flags().GetBool("check")
+		if err != nil {
+			return errors.New("invalid check flag")
+		}
+		write, err := cmd.Flags().GetBool("write")
+		if err != nil {
+			return errors.New("invalid write flag")
+		}
+
+		unformatted := 0
+		for _, file := range args {
+			bytes, err := readFileOrStdin(file)
+			if err != nil {
+				return errors.Errorf("error reading input file: %v", err)
+			}
+			schema, err := fgaschema.Parse(string(bytes))
+			if err != nil {
+				return errors.Errorf("%s:%v", file, err)
+			}
+			formatted := fgaschema.Format(schema)
+
+			switch {
+			case check:
+				if formatted != string(bytes) {
+					unformatted++
+					fmt.Println(file)
+				}
+			case write && file != "-":
+				if formatted == string(bytes) {
+					continue
+				}
+				info, err := os.Stat(file)
+				if err != nil {
+					return err
+				}
+				err = os.WriteFile(file, []byte(formatted), info.Mode())
+				if err != nil {
+					return errors.Errorf("error writing %s: %v", file, err)
+				}
+			default:
+				fmt.Print(formatted)
+			}
+		}
+
+		if unformatted > 0 {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var lintSchemaCmd = &cobra.Command{
+	Use:   "lint <input_file>...",
+	Short: "Lint a schema",
+	Long: "Check schemas for syntax errors, undefined types, relations and policies, relations and policies defined more than once, inheritance cycles and unused types " +
+		"without calling the API. Problems are reported as file:line:column and the command exits non-zero if there are any errors.",
+	Example: `workos fga schema lint schema.txt --strict`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			return errors.New("invalid strict flag")
+		}
+
+		var diagnostics []schemaDiagnostic
+		for _, file := range args {
+			bytes, err := readFileOrStdin(file)
+			if err != nil {
+				return errors.Errorf("error reading input file: %v", err)
+			}
+
+			var fileDiagnostics []fgaschema.Diagnostic
+			schema, err := fgaschema.Parse(string(bytes))
+			var parseErr *fgaschema.Error
+			if errors.As(err, &parseErr) {
+				fileDiagnostics = []fgaschema.Diagnostic{{Pos: parseErr.Pos, Severity: fgaschema.SeverityError, Message: parseErr.Msg}}
+			} else if err != nil {
+				return err
+			} else {
+				fileDiagnostics = fgaschema.Lint(schema)
+			}
+			for _, d := range fileDiagnostics {
+				diagnostics = append(diagnostics, schemaDiagnostic{
+					File:     file,
+					Line:     d.Pos.Line,
+					Column:   d.Pos.Column,
+					Severity: string(d.Severity),
+					Message:  d.Message,
+				})
+			}
+		}
+
+		failed := false
+		p := printer.NewListPrinter(120, "File", "Line", "Column", "Severity", "Message")
+		for _, d := range diagnostics {
+			failed = failed || d.Severity == string(fgaschema.SeverityError) || strict
+			if !printer.IsTable() {
+				p.Add(d, d.File, strconv.Itoa(d.Line), strconv.Itoa(d.Column), d.Severity, d.Message)
+				continue
+			}
+			severity := printer.YellowText(d.Severity)
+			if d.Severity == string(fgaschema.SeverityError) {
+				severity = printer.RedText(d.Severity)
+			}
+			printer.PrintMsg(fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, severity, d.Message))
+		}
+		if !printer.IsTable() {
+			p.Flush()
+		} else if len(diagnostics) == 0 {
+			printer.PrintMsg(printer.GreenText(printer.Checkmark, "No problems found"))
+		}
+
+		if failed {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+var diffSchemaCmd = &cobra.Command{
+	Use:   "diff <input_file>",
+	Short: "Show how a schema differs from the current resource types",
+	Long: "Compare a schema, or a JSON file of resource types, against the environment's current resource types and print " +
+		"the types and relations that applying it would add, remove or change.",
+	Example: `workos fga schema diff schema.txt`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		bytes, err := os.ReadFile(args[0])
+		if err != nil {
+			return errors.Errorf("error reading input file: %v", err)
+		}
+
+		var desired []fga.UpdateResourceTypeOpts
+		if strings.EqualFold(filepath.Ext(args[0]), ".json") {
+			err = json.Unmarshal(bytes, &desired)
+			if err != nil {
+				return errors.Errorf("error unmarshalling resource types: %v", err)
+			}
+		} else {
+			response, err := clients.fga.ConvertSchemaToResourceTypes(context.Background(), fga.ConvertSchemaToResourceTypesOpts{
+				Schema: string(bytes),
+			})
+			if err != nil {
+				return convertSchemaError(err)
+			}
+			for _, rt := range response.ResourceTypes {
+				desired = append(desired, fga.UpdateResourceTypeOpts(rt))
+			}
+		}
+
+		current, err := listAllResourceTypes(clients.fga)
+		if err != nil {
+			return errors.Errorf("error listing resource types: %v", err)
+		}
+		diff := diffResourceTypes(current, desired)
+		printResourceTypeDiff(diff)
+		if !printer.IsTable() {
+			printer.Print(diff)
+		}
+		return nil
+	},
+}
+
+var exportSchemaCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the current schema",
+	Long: "Export the environment's resource types as a schema, or as JSON that can be used with 'workos fga resourcetype apply', " +
+		"so the deployed model can be version controlled and checked for drift.",
+	Example: `workos fga schema export --file schema.txt
+workos fga schema export --format json --file resource-types.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		file, err := cmd.Flags().GetString("file")
+		if err != nil {
+			return errors.New("invalid file flag")
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return errors.New("invalid format flag")
+		}
+		schemaVersion, err := cmd.Flags().GetString("schema-version")
+		if err != nil {
+			return errors.New("invalid schema-version flag")
+		}
+
+		resourceTypes, err := listAllResourceTypes(clients.fga)
+		if err != nil {
+			return errors.Errorf("error listing resource types: %v", err)
+		}
+		sort.Slice(resourceTypes, func(i, j int) bool { return resourceTypes[i].Type < resourceTypes[j].Type })
+
+		var contents []byte
+		switch format {
+		case "schema":
+			response, err := clients.fga.ConvertResourceTypesToSchema(context.Background(), fga.ConvertResourceTypesToSchemaOpts{
+				Version:       schemaVersion,
+				ResourceTypes: resourceTypes,
+			})
+			if err != nil {
+				return convertSchemaError(err)
+			}
+			for _, warning := range response.Warnings {
+				printer.PrintWarning(warning.Message)
+			}
+			if response.Schema == nil {
+				return errors.New("error converting schema: no schema returned")
+			}
+			contents = []byte(*response.Schema)
+			if !strings.HasSuffix(*response.Schema, "\n") {
+				contents = append(contents, '\n')
+			}
+		case "json":
+			contents, err = json.MarshalIndent(resourceTypes, "", "    ")
+			if err != nil {
+				return err
+			}
+			contents = append(contents, '\n')
+		default:
+			return errors.Errorf("invalid format: %s", format)
+		}
+
+		if file == "" {
+			fmt.Print(string(contents))
+			return nil
+		}
+		err = os.WriteFile(file, contents, 0600)
+		if err != nil {
+			return errors.Errorf("error writing %s: %v", file, err)
+		}
+		printer.PrintMsg(fmt.Sprintf("Exported %d resource types to %s", len(resourceTypes), file))
+		return nil
+	},
+}
+
+// resourceTypeDiff is the set of changes applying resource types would make
+type resourceTypeDiff struct {
+	Added   []string             `json:"added"`
+	Removed []string             `json:"removed"`
+	Changed []resourceTypeChange `json:"changed"`
+}
+
+type resourceTypeChange struct {
+	Type             string   `json:"type"`
+	AddedRelations   []string `json:"added_relations"`
+	RemovedRelations []string `json:"removed_relations"`
+	ChangedRelations []string `json:"changed_relations"`
+}
+
+func (d resourceTypeDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// destructive reports whether the changes remove or redefine existing types or relations
+func (d resourceTypeDiff) destructive() bool {
+	if len(d.Removed) > 0 {
+		return true
+	}
+	for _, change := range d.Changed {
+		if len(change.RemovedRelations) > 0 || len(change.ChangedRelations) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func diffResourceTypes(current []fga.ResourceType, desired []fga.UpdateResourceTypeOpts) resourceTypeDiff {
+	diff := resourceTypeDiff{Added: []string{}, Removed: []string{}, Changed: []resourceTypeChange{}}
+	existing := make(map[string]map[string]interface{}, len(current))
+	for _, rt := range current {
+		existing[rt.Type] = rt.Relations
+	}
+	wanted := make(map[string]bool, len(desired))
+
+	for _, rt := range desired {
+		wanted[rt.Type] = true
+		relations, ok := existing[rt.Type]
+		if !ok {
+			diff.Added = append(diff.Added, rt.Type)
+			continue
+		}
+		change := resourceTypeChange{Type: rt.Type, AddedRelations: []string{}, RemovedRelations: []string{}, ChangedRelations: []string{}}
+		for name, relation := range rt.Relations {
+			existingRelation, ok := relations[name]
+			if !ok {
+				change.AddedRelations = append(change.AddedRelations, name)
+			} else if !jsonEqual(existingRelation, relation) {
+				change.ChangedRelations = append(change.ChangedRelations, name)
+			}
+		}
+		for name := range relations {
+			if _, ok := rt.Relations[name]; !ok {
+				change.RemovedRelations = append(change.RemovedRelations, name)
+			}
+		}
+		if len(change.AddedRelations)+len(change.RemovedRelations)+len(change.ChangedRelations) > 0 {
+			sort.Strings(change.AddedRelations)
+			sort.Strings(change.RemovedRelations)
+			sort.Strings(change.ChangedRelations)
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for _, rt := range current {
+		if !wanted[rt.Type] {
+			diff.Removed = append(diff.Removed, rt.Type)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Type < diff.Changed[j].Type })
+	return diff
+}
+
+// printResourceTypeDiff prints a readable diff when the table output format is selected
+func printResourceTypeDiff(diff resourceTypeDiff) {
+	if !printer.IsTable() {
+		return
+	}
+	if diff.empty() {
+		printer.PrintMsg("No changes")
+		return
+	}
+	for _, t := range diff.Added {
+		printer.PrintMsg(printer.GreenText("+ type " + t))
+	}
+	for _, t := range diff.Removed {
+		printer.PrintMsg(printer.RedText("- type " + t))
+	}
+	for _, change := range diff.Changed {
+		printer.PrintMsg(printer.YellowText("~ type " + change.Type))
+		for _, r := range change.AddedRelations {
+			printer.PrintMsg(printer.GreenText("    + relation " + r))
+		}
+		for _, r := range change.RemovedRelations {
+			printer.PrintMsg(printer.RedText("    - relation " + r))
+		}
+		for _, r := range change.ChangedRelations {
+			printer.PrintMsg(printer.YellowText("    ~ relation " + r))
+		}
+	}
+}
+
+// planResourceTypeChanges prints the changes applying resource types would make and
+// reports whether to go ahead, asking for confirmation before destructive changes
+func planResourceTypeChanges(cmd *cobra.Command, client *fga.Client, desired []fga.UpdateResourceTypeOpts) (bool, error) {
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return false, errors.New("invalid dry-run flag")
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return false, errors.New("invalid yes flag")
+	}
+
+	current, err := listAllResourceTypes(client)
+	if err != nil {
+		return false, errors.Errorf("error listing resource types: %v", err)
+	}
+	diff := diffResourceTypes(current, desired)
+	printResourceTypeDiff(diff)
+
+	if dryRun {
+		printer.PrintResult("Dry run, no changes applied", diff)
+		return false, nil
+	}
+	if diff.empty() {
+		printer.PrintResult("Resource types are up to date", diff)
+		return false, nil
+	}
+	if !diff.destructive() || yes {
+		return true, nil
+	}
+	return confirmChanges("This removes or changes existing resource types and relations. Apply?")
+}
+
+// confirmChanges asks the user to confirm destructive changes
+func confirmChanges(title string) (bool, error) {
+	var confirmed bool
+	err := huh.NewConfirm().
+		Title(title).
+		Value(&confirmed).
+		Run()
+	if err != nil {
+		return false, errors.Errorf("unable to confirm changes (use --yes to apply without confirmation): %v", err)
+	}
+	if !confirmed {
+		printer.PrintMsg("No changes applied")
+	}
+	return confirmed, nil
+}
+
+// addPlanFlags adds the flags for previewing and confirming changes to an apply command
+func addPlanFlags(flags *pflag.FlagSet) {
+	flags.Bool("dry-run", false, "print the changes without applying them (alias --plan)")
+	addYesFlag(flags, "apply destructive changes without asking for confirmation")
+	flags.SetNormalizeFunc(func(f *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "plan" {
+			name = "dry-run"
+		}
+		return pflag.NormalizedName(name)
+	})
+}
+
+// listAllResourceTypes fetches every resource type using client
+func listAllResourceTypes(client *fga.Client) ([]fga.ResourceType, error) {
+	resourceTypes := []fga.ResourceType{}
+	_, err := list.Walk(
+		list.Options{All: true},
+		func(after string) ([]fga.ResourceType, common.ListMetadata, error) {
+			res, err := client.ListResourceTypes(context.Background(), fga.ListResourceTypesOpts{
+				Limit: list.MaxPageSize,
+				After: after,
+			})
+			return res.Data, res.ListMetadata, err
+		},
+		func(resourceType fga.ResourceType) error {
+			resourceTypes = append(resourceTypes, resourceType)
+			return nil
+		},
+	)
+	return resourceTypes, err
+}
+
+// jsonEqual compares two values by their JSON encoding
+func jsonEqual(a any, b any) bool {
+	aBytes, aErr := json.Marshal(a)
+	bBytes, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && string(aBytes) == string(bBytes)
+}
+
+// fgaOrder converts an order flag value to an fga.Order, leaving it unset when empty
+func fgaOrder(order string) fga.Order {
+	if order == "" {
+		return ""
+	}
+	if strings.ToLower(order) == "asc" {
+		return fga.Asc
+	}
+	return fga.Desc
+}
+
 func convertSchemaError(err error) error {
 	var target workos_errors.HTTPError
 	if errors.As(err, &target) {
@@ -772,6 +1658,277 @@ func convertSchemaError(err error) error {
 	return errors.Errorf("error converting schema: %v", err)
 }
 
+func (s warrantSpec) warrant() (fga.Warrant, error) {
+	subject, err := parseSubject(s.Subject)
+	if err != nil {
+		return fga.Warrant{}, err
+	}
+	if s.Relation == "" {
+		return fga.Warrant{}, errors.New("missing relation")
+	}
+	resourceType, resourceId, err := parseResource(s.Resource)
+	if err != nil {
+		return fga.Warrant{}, err
+	}
+	return fga.Warrant{
+		ResourceType: resourceType,
+		ResourceId:   resourceId,
+		Relation:     s.Relation,
+		Subject:      subject,
+		Policy:       s.Policy,
+	}, nil
+}
+
+// parseSubject parses a subject of the form type:id or type:id#relation
+func parseSubject(s string) (fga.Subject, error) {
+	subjectType, subjectIdRelation, valid := strings.Cut(s, ":")
+	if !valid || subjectType == "" || subjectIdRelation == "" {
+		return fga.Subject{}, errors.Errorf("invalid subject: %s", s)
+	}
+	subjectId, subjectRelation, _ := strings.Cut(subjectIdRelation, "#")
+	return fga.Subject{
+		ResourceType: subjectType,
+		ResourceId:   subjectId,
+		Relation:     subjectRelation,
+	}, nil
+}
+
+// parseResource parses a resource of the form type:id
+func parseResource(s string) (string, string, error) {
+	resourceType, resourceId, valid := strings.Cut(s, ":")
+	if !valid || resourceType == "" || resourceId == "" {
+		return "", "", errors.Errorf("invalid resource: %s", s)
+	}
+	return resourceType, resourceId, nil
+}
+
+func subjectAsString(s fga.Subject) string {
+	if s.Relation != "" {
+		return fmt.Sprintf("%s:%s#%s", s.ResourceType, s.ResourceId, s.Relation)
+	}
+	return fmt.Sprintf("%s:%s", s.ResourceType, s.ResourceId)
+}
+
+// warrantAsString formats a warrant the way it's passed to warrant create, which also makes it a unique key
+func warrantAsString(w fga.Warrant) string {
+	s := fmt.Sprintf("%s %s %s:%s", subjectAsString(w.Subject), w.Relation, w.ResourceType, w.ResourceId)
+	if w.Policy != "" {
+		s = fmt.Sprintf("%s [%s]", s, w.Policy)
+	}
+	return s
+}
+
+func sortWarrants(warrants []fga.Warrant) {
+	sort.Slice(warrants, func(i, j int) bool {
+		return warrantAsString(warrants[i]) < warrantAsString(warrants[j])
+	})
+}
+
+func warrantWriteOpts(op string, w fga.Warrant) fga.WriteWarrantOpts {
+	return fga.WriteWarrantOpts{
+		Op:           op,
+		ResourceType: w.ResourceType,
+		ResourceId:   w.ResourceId,
+		Relation:     w.Relation,
+		Subject:      w.Subject,
+		Policy:       w.Policy,
+	}
+}
+
+// listAllWarrants fetches every warrant matching the filters in opts
+func listAllWarrants(client *fga.Client, opts fga.ListWarrantsOpts) ([]fga.Warrant, error) {
+	var warrants []fga.Warrant
+	_, err := list.Walk(
+		list.Options{All: true},
+		func(after string) ([]fga.Warrant, common.ListMetadata, error) {
+			opts.Limit = list.MaxPageSize
+			opts.After = after
+			res, err := client.ListWarrants(context.Background(), opts)
+			return res.Data, res.ListMetadata, err
+		},
+		func(warrant fga.Warrant) error {
+			warrants = append(warrants, warrant)
+			return nil
+		},
+	)
+	return warrants, err
+}
+
+// newCheckResult builds the result of a check, evaluating it against the expected result if there is one
+func newCheckResult(check fga.WarrantCheck, response fga.CheckResponse, expect *bool) checkResult {
+	res := checkResult{
+		Check:      check,
+		Result:     response.Result,
+		Authorized: response.Authorized(),
+		Assert:     expect,
+	}
+	if expect != nil {
+		passed := *expect == res.Authorized
+		res.Passed = &passed
+	}
+	return res
+}
+
+func (s checkSpec) warrantCheck() (fga.WarrantCheck, error) {
+	subject, err := parseSubject(s.Subject)
+	if err != nil {
+		return fga.WarrantCheck{}, err
+	}
+	if s.Relation == "" {
+		return fga.WarrantCheck{}, errors.New("missing relation")
+	}
+	resourceType, resourceId, err := parseResource(s.Resource)
+	if err != nil {
+		return fga.WarrantCheck{}, err
+	}
+	return fga.WarrantCheck{
+		ResourceType: resourceType,
+		ResourceId:   resourceId,
+		Relation:     s.Relation,
+		Subject:      subject,
+		Context:      s.Context,
+	}, nil
+}
+
+// readCheckSpecs reads checks from a JSON, YAML or CSV file. Files without a
+// .csv extension (including stdin) are parsed as YAML, falling back to CSV.
+func readCheckSpecs(file string) ([]checkSpec, error) {
+	bytes, err := readFileOrStdin(file)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(file), ".csv") {
+		return parseCheckSpecsCsv(bytes)
+	}
+
+	var specs []checkSpec
+	yamlErr := yaml.Unmarshal(bytes, &specs)
+	if yamlErr == nil {
+		return specs, nil
+	}
+	specs, err = parseCheckSpecsCsv(bytes)
+	if err != nil {
+		return nil, yamlErr
+	}
+	return specs, nil
+}
+
+// parseCheckSpecsCsv parses checks from CSV with a header row of
+// subject, relation, resource and optionally context (as JSON) and expect
+func parseCheckSpecsCsv(bytes []byte) ([]checkSpec, error) {
+	records, err := csv.NewReader(strings.NewReader(string(bytes))).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"subject", "relation", "resource"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errors.Errorf("missing %s column", required)
+		}
+	}
+
+	var specs []checkSpec
+	for i, record := range records[1:] {
+		spec := checkSpec{
+			Subject:  record[columns["subject"]],
+			Relation: record[columns["relation"]],
+			Resource: record[columns["resource"]],
+		}
+		if col, ok := columns["context"]; ok && record[col] != "" {
+			err := json.Unmarshal([]byte(record[col]), &spec.Context)
+			if err != nil {
+				return nil, errors.Errorf("invalid context on row %d: %s", i+2, record[col])
+			}
+		}
+		if col, ok := columns["expect"]; ok && record[col] != "" {
+			expect, err := strconv.ParseBool(record[col])
+			if err != nil {
+				return nil, errors.Errorf("invalid expect on row %d: %s", i+2, record[col])
+			}
+			spec.Expect = &expect
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// runCheckBatch evaluates the checks in a batch file in chunks, printing a
+// result per check and a summary, and exits non-zero if an expectation fails
+func runCheckBatch(client *fga.Client, file string, warrantToken string) error {
+	specs, err := readCheckSpecs(file)
+	if err != nil {
+		return errors.Errorf("invalid batch file: %v", err)
+	}
+	checks := make([]fga.WarrantCheck, len(specs))
+	for i, spec := range specs {
+		checks[i], err = spec.warrantCheck()
+		if err != nil {
+			return errors.Errorf("invalid check at index %d: %v", i, err)
+		}
+	}
+
+	var results []fga.CheckResponse
+	for start := 0; start < len(checks); start += checkBatchSize {
+		end := min(start+checkBatchSize, len(checks))
+		res, err := client.CheckBatch(context.Background(), fga.CheckBatchOpts{
+			Checks:       checks[start:end],
+			WarrantToken: warrantToken,
+		})
+		if err != nil {
+			return errors.Errorf("error evaluating checks: %v", err)
+		}
+		results = append(results, res...)
+	}
+	if len(results) != len(checks) {
+		return errors.Errorf("error evaluating checks: expected %d results, got %d", len(checks), len(results))
+	}
+
+	summary := checkBatchSummary{Total: len(checks)}
+	p := printer.NewListPrinter(120, "Check", "Result", "Expected", "Status")
+	for i, check := range checks {
+		res := newCheckResult(check, results[i], specs[i].Expect)
+		status := ""
+		if res.Passed != nil {
+			if *res.Passed {
+				summary.Passed++
+				status = "pass"
+				if printer.IsTable() {
+					status = printer.GreenText(printer.Checkmark, status)
+				}
+			} else {
+				summary.Failed++
+				status = "fail"
+				if printer.IsTable() {
+					status = printer.RedText(printer.Cross, status)
+				}
+			}
+		}
+		expected := ""
+		if res.Assert != nil {
+			expected = strconv.FormatBool(*res.Assert)
+		}
+		checkString, err := warrantCheckAsString(check)
+		if err != nil {
+			return errors.Errorf("invalid check: %v", err)
+		}
+		p.Add(res, checkString, res.Result, expected, status)
+	}
+	p.Flush()
+
+	printer.PrintMsg(fmt.Sprintf("%d checks, %d passed, %d failed, %d without expectations",
+		summary.Total, summary.Passed, summary.Failed, summary.Total-summary.Passed-summary.Failed))
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
 func warrantCheckAsString(w fga.WarrantCheck) (string, error) {
 	s := fmt.Sprintf(
 		"%s:%s %s %s:%s",
@@ -792,7 +1949,7 @@ func warrantCheckAsString(w fga.WarrantCheck) (string, error) {
 	return s, nil
 }
 
-func buildDecisionTreeList(node fga.DecisionTreeNode) *list.List {
+func buildDecisionTreeList(node fga.DecisionTreeNode) *lipglossList.List {
 	checkText := fmt.Sprintf(
 		"%s:%s#%s@%s:%s",
 		node.Check.ResourceType,
@@ -819,7 +1976,7 @@ func buildDecisionTreeList(node fga.DecisionTreeNode) *list.List {
 	}
 
 	checkText = fmt.Sprintf("%s (%dms)", checkText, node.ProcessingTime/1000000)
-	tree := list.New(checkText).Enumerator(list.Tree)
+	tree := lipglossList.New(checkText).Enumerator(lipglossList.Tree)
 	for _, child := range node.Children {
 		tree.Item(buildDecisionTreeList(child))
 	}
@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
+	"github.com/workos/workos-go/v4/pkg/fga"
+)
+
+const (
+	SnapshotFormatVersion     = 1
+	SnapshotManifestFile      = "manifest.json"
+	SnapshotResourceTypesFile = "resource-types.json"
+	SnapshotResourcesFile     = "resources.json"
+	SnapshotWarrantsFile      = "warrants.json"
+
+	// snapshotProgressInterval is how many resources are restored between progress messages
+	snapshotProgressInterval = 100
+)
+
+func init() {
+	createSnapshotCmd.Flags().StringP("dir", "d", "", "directory to write the snapshot to")
+	_ = createSnapshotCmd.MarkFlagRequired("dir")
+	snapshotCmd.AddCommand(createSnapshotCmd)
+	restoreSnapshotCmd.Flags().Bool("prune", false, "delete resources and warrants that are not in the snapshot")
+	addPlanFlags(restoreSnapshotCmd.Flags())
+	guard(restoreSnapshotCmd)
+	snapshotCmd.AddCommand(restoreSnapshotCmd)
+	fgaCmd.AddCommand(snapshotCmd)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Snapshot and restore FGA data",
+	Long:  "Export resource types, resources and warrants to a directory and restore them into an environment, e.g. to clone staging data into a sandbox or roll back seeded test data.",
+}
+
+// snapshotManifest describes the contents of a snapshot directory
+type snapshotManifest struct {
+	Version       int       `json:"version"`
+	Environment   string    `json:"environment"`
+	CreatedAt     time.Time `json:"created_at"`
+	ResourceTypes int       `json:"resource_types"`
+	Resources     int       `json:"resources"`
+	Warrants      int       `json:"warrants"`
+}
+
+var createSnapshotCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a snapshot",
+	Long: "Export all resource types, resources with their metadata and warrants into a directory of JSON files: " +
+		SnapshotManifestFile + ", " + SnapshotResourceTypesFile + ", " + SnapshotResourcesFile + " and " + SnapshotWarrantsFile + ".",
+	Example: "workos fga snapshot create --dir snapshots/staging",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := cmd.Flags().GetString("dir")
+		if err != nil {
+			return errors.New("invalid dir flag")
+		}
+
+		clients := newClients()
+		printer.PrintMsg("Exporting resource types...")
+		resourceTypes, err := listAllResourceTypes(clients.fga)
+		if err != nil {
+			return errors.Errorf("error listing resource types: %v", err)
+		}
+		printer.PrintMsg("Exporting resources...")
+		resources, err := listAllResources(clients.fga)
+		if err != nil {
+			return errors.Errorf("error listing resources: %v", err)
+		}
+		printer.PrintMsg("Exporting warrants...")
+		warrants, err := listAllWarrants(clients.fga, fga.ListWarrantsOpts{})
+		if err != nil {
+			return errors.Errorf("error listing warrants: %v", err)
+		}
+		if warrants == nil {
+			warrants = []fga.Warrant{}
+		}
+
+		manifest := snapshotManifest{
+			Version:       SnapshotFormatVersion,
+			Environment:   activeEnvName,
+			CreatedAt:     time.Now().UTC(),
+			ResourceTypes: len(resourceTypes),
+			Resources:     len(resources),
+			Warrants:      len(warrants),
+		}
+
+		err = os.MkdirAll(dir, 0700)
+		if err != nil {
+			return errors.Errorf("error creating snapshot directory: %v", err)
+		}
+		files := map[string]any{
+			SnapshotManifestFile:      manifest,
+			SnapshotResourceTypesFile: resourceTypes,
+			SnapshotResourcesFile:     resources,
+			SnapshotWarrantsFile:      warrants,
+		}
+		for name, val := range files {
+			bytes, err := json.MarshalIndent(val, "", "    ")
+			if err != nil {
+				return err
+			}
+			err = os.WriteFile(filepath.Join(dir, name), append(bytes, '\n'), 0600)
+			if err != nil {
+				return errors.Errorf("error writing %s: %v", name, err)
+			}
+		}
+
+		printer.PrintResult(
+			fmt.Sprintf("Snapshot of %d resource types, %d resources and %d warrants written to %s",
+				manifest.ResourceTypes, manifest.Resources, manifest.Warrants, dir),
+			manifest,
+		)
+		return nil
+	},
+}
+
+var restoreSnapshotCmd = &cobra.Command{
+	Use:   "restore <dir>",
+	Short: "Restore a snapshot",
+	Long: "Restore a snapshot into the active environment. Resource types are replaced by the snapshot's, missing resources and warrants " +
+		"are created and resource metadata is updated to match. With --prune, resources and warrants that aren't in the snapshot are deleted.",
+	Example: "workos fga snapshot restore snapshots/staging --prune",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		prune, err := cmd.Flags().GetBool("prune")
+		if err != nil {
+			return errors.New("invalid prune flag")
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return errors.New("invalid dry-run flag")
+		}
+
+		dir := args[0]
+		var manifest snapshotManifest
+		var resourceTypes []fga.ResourceType
+		var resources []fga.Resource
+		var warrants []fga.Warrant
+		files := map[string]any{
+			SnapshotManifestFile:      &manifest,
+			SnapshotResourceTypesFile: &resourceTypes,
+			SnapshotResourcesFile:     &resources,
+			SnapshotWarrantsFile:      &warrants,
+		}
+		for name, val := range files {
+			bytes, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return errors.Errorf("error reading snapshot: %v", err)
+			}
+			err = json.Unmarshal(bytes, val)
+			if err != nil {
+				return errors.Errorf("error reading %s: %v", name, err)
+			}
+		}
+		if manifest.Version != SnapshotFormatVersion {
+			return errors.Errorf("unsupported snapshot version: %d", manifest.Version)
+		}
+
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return errors.New("invalid yes flag")
+		}
+
+		// Resource types
+		ops := make([]fga.UpdateResourceTypeOpts, 0, len(resourceTypes))
+		for _, rt := range resourceTypes {
+			ops = append(ops, fga.UpdateResourceTypeOpts(rt))
+		}
+		currentResourceTypes, err := listAllResourceTypes(clients.fga)
+		if err != nil {
+			return errors.Errorf("error listing resource types: %v", err)
+		}
+		resourceTypeChanges := diffResourceTypes(currentResourceTypes, ops)
+		if printer.IsTable() {
+			printer.PrintMsg("Resource types:")
+			printResourceTypeDiff(resourceTypeChanges)
+		}
+
+		// Resources
+		currentResources, err := listAllResources(clients.fga)
+		if err != nil {
+			return errors.Errorf("error listing resources: %v", err)
+		}
+		existingResources := make(map[string]fga.Resource, len(currentResources))
+		for _, resource := range currentResources {
+			existingResources[resourceKey(resource)] = resource
+		}
+		wantedResources := make(map[string]bool, len(resources))
+		var createResources, updateResources, deleteResources []fga.Resource
+		for _, resource := range resources {
+			wantedResources[resourceKey(resource)] = true
+			existing, ok := existingResources[resourceKey(resource)]
+			if !ok {
+				createResources = append(createResources, resource)
+			} else if !jsonEqual(existing.Meta, resource.Meta) {
+				updateResources = append(updateResources, resource)
+			}
+		}
+		if prune {
+			for _, resource := range currentResources {
+				if !wantedResources[resourceKey(resource)] {
+					deleteResources = append(deleteResources, resource)
+				}
+			}
+		}
+
+		// Warrants
+		currentWarrants, err := listAllWarrants(clients.fga, fga.ListWarrantsOpts{})
+		if err != nil {
+			return errors.Errorf("error listing warrants: %v", err)
+		}
+		existingWarrants := make(map[string]bool, len(currentWarrants))
+		for _, warrant := range currentWarrants {
+			existingWarrants[warrantAsString(warrant)] = true
+		}
+		wantedWarrants := make(map[string]bool, len(warrants))
+		var writes []fga.WriteWarrantOpts
+		deleteWarrants := 0
+		for _, warrant := range warrants {
+			wantedWarrants[warrantAsString(warrant)] = true
+			if !existingWarrants[warrantAsString(warrant)] {
+				writes = append(writes, warrantWriteOpts(fga.WarrantOpCreate, warrant))
+			}
+		}
+		if prune {
+			for _, warrant := range currentWarrants {
+				if !wantedWarrants[warrantAsString(warrant)] {
+					writes = append(writes, warrantWriteOpts(fga.WarrantOpDelete, warrant))
+					deleteWarrants++
+				}
+			}
+		}
+
+		summary := snapshotRestoreSummary{
+			ResourceTypes:    resourceTypeChanges,
+			ResourcesCreated: len(createResources),
+			ResourcesUpdated: len(updateResources),
+			ResourcesDeleted: len(deleteResources),
+			WarrantWrites:    len(writes),
+		}
+		if dryRun {
+			printer.PrintResult(
+				fmt.Sprintf("Resources: %d to create, %d to update, %d to delete\nWarrants: %d to write",
+					summary.ResourcesCreated, summary.ResourcesUpdated, summary.ResourcesDeleted, summary.WarrantWrites),
+				summary,
+			)
+			return nil
+		}
+
+		// Everything is planned before anything is written, so declining leaves the environment untouched
+		if !yes {
+			var confirmations []string
+			if resourceTypeChanges.destructive() {
+				confirmations = append(confirmations, "removes or changes existing resource types and relations")
+			}
+			if len(deleteResources) > 0 || deleteWarrants > 0 {
+				confirmations = append(confirmations, fmt.Sprintf("deletes %d resources and %d warrants that aren't in the snapshot", len(deleteResources), deleteWarrants))
+			}
+			if len(confirmations) > 0 {
+				confirmed, err := confirmChanges(fmt.Sprintf("This %s. Restore?", strings.Join(confirmations, " and ")))
+				if err != nil {
+					return err
+				}
+				if !confirmed {
+					return errors.New("restore cancelled")
+				}
+			}
+		}
+
+		if !resourceTypeChanges.empty() {
+			_, err = clients.fga.BatchUpdateResourceTypes(context.Background(), ops)
+			if err != nil {
+				return errors.Errorf("error restoring resource types: %v", err)
+			}
+			printer.PrintMsg(fmt.Sprintf("Resource types: %d restored", len(ops)))
+		}
+
+		done := 0
+		total := len(createResources) + len(updateResources)
+		for _, resource := range createResources {
+			_, err := clients.fga.CreateResource(context.Background(), fga.CreateResourceOpts{
+				ResourceType: resource.ResourceType,
+				ResourceId:   resource.ResourceId,
+				Meta:         resource.Meta,
+			})
+			if err != nil {
+				return errors.Errorf("error creating resource %s: %v", resourceKey(resource), err)
+			}
+			done++
+			printSnapshotProgress("Resources", done, total)
+		}
+		for _, resource := range updateResources {
+			_, err := clients.fga.UpdateResource(context.Background(), fga.UpdateResourceOpts{
+				ResourceType: resource.ResourceType,
+				ResourceId:   resource.ResourceId,
+				Meta:         resource.Meta,
+			})
+			if err != nil {
+				return errors.Errorf("error updating resource %s: %v", resourceKey(resource), err)
+			}
+			done++
+			printSnapshotProgress("Resources", done, total)
+		}
+
+		for start := 0; start < len(writes); start += warrantBatchSize {
+			end := min(start+warrantBatchSize, len(writes))
+			res, err := clients.fga.BatchWriteWarrants(context.Background(), writes[start:end])
+			if err != nil {
+				return errors.Errorf("error writing warrants (%d of %d written): %v", start, len(writes), err)
+			}
+			summary.WarrantToken = res.WarrantToken
+			printer.PrintMsg(fmt.Sprintf("Warrants: %d/%d", end, len(writes)))
+		}
+
+		// Resources are deleted last since deleting a resource also deletes its warrants
+		for i, resource := range deleteResources {
+			err := clients.fga.DeleteResource(context.Background(), fga.DeleteResourceOpts{
+				ResourceType: resource.ResourceType,
+				ResourceId:   resource.ResourceId,
+			})
+			if err != nil {
+				return errors.Errorf("error deleting resource %s: %v", resourceKey(resource), err)
+			}
+			printSnapshotProgress("Deleted resources", i+1, len(deleteResources))
+		}
+
+		printer.PrintResult(
+			fmt.Sprintf("Snapshot restored: %d resources created, %d updated, %d deleted and %d warrants written",
+				summary.ResourcesCreated, summary.ResourcesUpdated, summary.ResourcesDeleted, summary.WarrantWrites),
+			summary,
+		)
+		return nil
+	},
+}
+
+// snapshotRestoreSummary counts the changes made by snapshot restore
+type snapshotRestoreSummary struct {
+	ResourceTypes    resourceTypeDiff `json:"resource_types"`
+	ResourcesCreated int              `json:"resources_created"`
+	ResourcesUpdated int              `json:"resources_updated"`
+	ResourcesDeleted int              `json:"resources_deleted"`
+	WarrantWrites    int              `json:"warrant_writes"`
+	WarrantToken     string           `json:"warrant_token,omitempty"`
+}
+
+func printSnapshotProgress(label string, done int, total int) {
+	if done%snapshotProgressInterval == 0 || done == total {
+		printer.PrintMsg(fmt.Sprintf("%s: %d/%d", label, done, total))
+	}
+}
+
+func resourceKey(r fga.Resource) string {
+	return r.ResourceType + ":" + r.ResourceId
+}
+
+// listAllResources fetches every resource using client
+func listAllResources(client *fga.Client) ([]fga.Resource, error) {
+	resources := []fga.Resource{}
+	_, err := list.Walk(
+		list.Options{All: true},
+		func(after string) ([]fga.Resource, common.ListMetadata, error) {
+			res, err := client.ListResources(context.Background(), fga.ListResourcesOpts{
+				Limit: list.MaxPageSize,
+				After: after,
+			})
+			return res.Data, res.ListMetadata, err
+		},
+		func(resource fga.Resource) error {
+			resources = append(resources, resource)
+			return nil
+		},
+	)
+	return resources, err
+}
@@ -0,0 +1,554 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
+	"github.com/workos/workos-go/v4/pkg/fga"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ReporterJunit = "junit"
+	ReporterTap   = "tap"
+)
+
+func init() {
+	fgaTestCmd.Flags().String("reporter", ReporterTap, "report format (tap or junit)")
+	fgaTestCmd.Flags().String("report-file", "", "file to write the report to (defaults to stdout)")
+	fgaTestCmd.Flags().Bool("keep-fixtures", false, "leave fixture resources, warrants and the applied schema in place after the tests run")
+	guard(fgaTestCmd)
+	fgaCmd.AddCommand(fgaTestCmd)
+}
+
+// fgaTestSuite is an authorization test suite as written in a suite file
+type fgaTestSuite struct {
+	Name       string             `yaml:"name"`
+	Schema     string             `yaml:"schema"`
+	SchemaFile string             `yaml:"schema_file"`
+	Resources  []fgaTestResource  `yaml:"resources"`
+	Warrants   []warrantSpec      `yaml:"warrants"`
+	Tests      []fgaTestAssertion `yaml:"tests"`
+	path       string
+	fixtures   fgaTestSuiteFixtures
+}
+
+type fgaTestResource struct {
+	Resource string                 `yaml:"resource"`
+	Meta     map[string]interface{} `yaml:"meta"`
+}
+
+// fgaTestAssertion is either a check, expecting true or false, or a query,
+// expecting the list of resources (type:id) it returns
+type fgaTestAssertion struct {
+	Name    string      `yaml:"name"`
+	Check   *checkSpec  `yaml:"check"`
+	Query   string      `yaml:"query"`
+	Context fga.Context `yaml:"context"`
+	Expect  any         `yaml:"expect"`
+}
+
+// fgaTestSuiteFixtures tracks the fixtures created for a suite so they can be torn down.
+// Resources and warrants that already existed aren't tracked, so teardown leaves them alone.
+type fgaTestSuiteFixtures struct {
+	resources    []fga.Resource
+	warrants     []fga.Warrant
+	warrantToken string
+	// resourceTypes are the resource types from before the suite's schema was applied
+	resourceTypes []fga.ResourceType
+	schemaApplied bool
+}
+
+type fgaTestResult struct {
+	Name     string
+	Passed   bool
+	Message  string
+	Duration time.Duration
+}
+
+type fgaTestSuiteResult struct {
+	Name     string
+	Results  []fgaTestResult
+	Duration time.Duration
+}
+
+var fgaTestCmd = &cobra.Command{
+	Use:   "test <suite.yaml>...",
+	Short: "Run authorization test suites",
+	Long: "Run authorization test suites against your FGA model. Each suite can apply a schema, create fixture resources and warrants, " +
+		"and declares check and query assertions. Once the suite has run, the fixtures it created are deleted and the resource types " +
+		"it replaced are restored. Resources and warrants that already existed are left in place. " +
+		"Results are reported in TAP or JUnit XML format and the command exits non-zero if any assertion fails.",
+	Example: `workos fga test authz.yaml --reporter junit --report-file report.xml
+
+# authz.yaml
+name: documents
+schema_file: schema.txt
+resources:
+  - resource: document:xyz
+    meta: {"name": "Roadmap"}
+warrants:
+  - subject: user:john
+    relation: owner
+    resource: document:xyz
+tests:
+  - name: owners can edit
+    check: {subject: user:john, relation: editor, resource: document:xyz}
+    expect: true
+  - name: john's documents
+    query: select document where user:john is owner
+    expect: [document:xyz]`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reporter, err := cmd.Flags().GetString("reporter")
+		if err != nil {
+			return errors.New("invalid reporter flag")
+		}
+		if reporter != ReporterTap && reporter != ReporterJunit {
+			return errors.Errorf("invalid reporter: %s (must be %s or %s)", reporter, ReporterTap, ReporterJunit)
+		}
+		reportFile, err := cmd.Flags().GetString("report-file")
+		if err != nil {
+			return errors.New("invalid report-file flag")
+		}
+		keepFixtures, err := cmd.Flags().GetBool("keep-fixtures")
+		if err != nil {
+			return errors.New("invalid keep-fixtures flag")
+		}
+
+		var suites []*fgaTestSuite
+		for _, path := range args {
+			suite, err := readFgaTestSuite(path)
+			if err != nil {
+				return errors.Errorf("invalid suite %s: %v", path, err)
+			}
+			suites = append(suites, suite)
+		}
+
+		clients := newClients()
+		var suiteResults []fgaTestSuiteResult
+		failed := false
+		for _, suite := range suites {
+			suiteResult := suite.run(clients.fga, keepFixtures)
+			for _, result := range suiteResult.Results {
+				failed = failed || !result.Passed
+			}
+			suiteResults = append(suiteResults, suiteResult)
+		}
+
+		err = writeFgaTestReport(reporter, reportFile, suiteResults)
+		if err != nil {
+			return errors.Errorf("error writing report: %v", err)
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+func readFgaTestSuite(path string) (*fgaTestSuite, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	suite := &fgaTestSuite{path: path}
+	err = yaml.Unmarshal(bytes, suite)
+	if err != nil {
+		return nil, err
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if suite.Schema != "" && suite.SchemaFile != "" {
+		return nil, errors.New("only one of schema and schema_file can be set")
+	}
+	for i, assertion := range suite.Tests {
+		if (assertion.Check == nil) == (assertion.Query == "") {
+			return nil, errors.Errorf("test at index %d must have exactly one of check or query", i)
+		}
+	}
+	return suite, nil
+}
+
+// run sets up the suite's schema and fixtures, runs its assertions and tears the fixtures down.
+// Setup errors are reported as a failed setup result and fail every assertion in the suite.
+func (s *fgaTestSuite) run(client *fga.Client, keepFixtures bool) fgaTestSuiteResult {
+	start := time.Now()
+	suiteResult := fgaTestSuiteResult{Name: s.Name}
+
+	err := s.setup(client)
+	if err != nil {
+		// Reported on its own so the error isn't lost for a suite without tests
+		suiteResult.Results = append(suiteResult.Results, fgaTestResult{
+			Name:    "setup",
+			Message: err.Error(),
+		})
+		for i, assertion := range s.Tests {
+			suiteResult.Results = append(suiteResult.Results, fgaTestResult{
+				Name:    assertion.name(i),
+				Message: fmt.Sprintf("setup failed: %v", err),
+			})
+		}
+	} else {
+		for i, assertion := range s.Tests {
+			suiteResult.Results = append(suiteResult.Results, assertion.run(client, i, s.fixtures.warrantToken))
+		}
+	}
+
+	if !keepFixtures {
+		s.teardown(client)
+	}
+	suiteResult.Duration = time.Since(start)
+	return suiteResult
+}
+
+func (s *fgaTestSuite) setup(client *fga.Client) error {
+	schema := s.Schema
+	if s.SchemaFile != "" {
+		bytes, err := os.ReadFile(filepath.Join(filepath.Dir(s.path), s.SchemaFile))
+		if err != nil {
+			return errors.Errorf("error reading schema file: %v", err)
+		}
+		schema = string(bytes)
+	}
+	if schema != "" {
+		response, err := client.ConvertSchemaToResourceTypes(context.Background(), fga.ConvertSchemaToResourceTypesOpts{
+			Schema: schema,
+		})
+		if err != nil {
+			return convertSchemaError(err)
+		}
+		ops := make([]fga.UpdateResourceTypeOpts, 0)
+		for _, rt := range response.ResourceTypes {
+			ops = append(ops, fga.UpdateResourceTypeOpts(rt))
+		}
+		current, err := listAllResourceTypes(client)
+		if err != nil {
+			return errors.Errorf("error listing resource types: %v", err)
+		}
+		if !diffResourceTypes(current, ops).empty() {
+			_, err = client.BatchUpdateResourceTypes(context.Background(), ops)
+			if err != nil {
+				return errors.Errorf("error applying schema: %v", err)
+			}
+			s.fixtures.resourceTypes = current
+			s.fixtures.schemaApplied = true
+		}
+	}
+
+	existingResources := map[string]bool{}
+	if len(s.Resources) > 0 {
+		resources, err := listAllResources(client)
+		if err != nil {
+			return errors.Errorf("error listing resources: %v", err)
+		}
+		for _, resource := range resources {
+			existingResources[resourceKey(resource)] = true
+		}
+	}
+	for _, fixture := range s.Resources {
+		resourceType, resourceId, err := parseResource(fixture.Resource)
+		if err != nil {
+			return err
+		}
+		if existingResources[resourceType+":"+resourceId] {
+			continue
+		}
+		resource, err := client.CreateResource(context.Background(), fga.CreateResourceOpts{
+			ResourceType: resourceType,
+			ResourceId:   resourceId,
+			Meta:         fixture.Meta,
+		})
+		if err != nil {
+			return errors.Errorf("error creating resource %s: %v", fixture.Resource, err)
+		}
+		s.fixtures.resources = append(s.fixtures.resources, resource)
+	}
+
+	var warrants []fga.Warrant
+	for i, spec := range s.Warrants {
+		warrant, err := spec.warrant()
+		if err != nil {
+			return errors.Errorf("invalid warrant at index %d: %v", i, err)
+		}
+		warrants = append(warrants, warrant)
+	}
+	if len(warrants) > 0 {
+		existing, err := listAllWarrants(client, fga.ListWarrantsOpts{})
+		if err != nil {
+			return errors.Errorf("error listing warrants: %v", err)
+		}
+		existingWarrants := make(map[string]bool, len(existing))
+		for _, warrant := range existing {
+			existingWarrants[warrantAsString(warrant)] = true
+		}
+		missing := make([]fga.Warrant, 0, len(warrants))
+		for _, warrant := range warrants {
+			if !existingWarrants[warrantAsString(warrant)] {
+				missing = append(missing, warrant)
+			}
+		}
+		warrants = missing
+	}
+	for start := 0; start < len(warrants); start += warrantBatchSize {
+		batch := warrants[start:min(start+warrantBatchSize, len(warrants))]
+		var writes []fga.WriteWarrantOpts
+		for _, warrant := range batch {
+			writes = append(writes, warrantWriteOpts(fga.WarrantOpCreate, warrant))
+		}
+		res, err := client.BatchWriteWarrants(context.Background(), writes)
+		if err != nil {
+			return errors.Errorf("error creating warrants: %v", err)
+		}
+		s.fixtures.warrants = append(s.fixtures.warrants, batch...)
+		s.fixtures.warrantToken = res.WarrantToken
+	}
+	return nil
+}
+
+// teardown deletes the fixtures that setup created and restores the resource types its
+// schema replaced, warning about anything it can't undo
+func (s *fgaTestSuite) teardown(client *fga.Client) {
+	warrants := s.fixtures.warrants
+	for start := 0; start < len(warrants); start += warrantBatchSize {
+		var writes []fga.WriteWarrantOpts
+		for _, warrant := range warrants[start:min(start+warrantBatchSize, len(warrants))] {
+			writes = append(writes, warrantWriteOpts(fga.WarrantOpDelete, warrant))
+		}
+		_, err := client.BatchWriteWarrants(context.Background(), writes)
+		if err != nil {
+			printer.PrintWarning(fmt.Sprintf("error deleting warrant fixtures for suite %s: %v", s.Name, err))
+		}
+	}
+	for _, resource := range s.fixtures.resources {
+		err := client.DeleteResource(context.Background(), fga.DeleteResourceOpts{
+			ResourceType: resource.ResourceType,
+			ResourceId:   resource.ResourceId,
+		})
+		if err != nil {
+			printer.PrintWarning(fmt.Sprintf("error deleting resource fixture %s:%s: %v", resource.ResourceType, resource.ResourceId, err))
+		}
+	}
+	if s.fixtures.schemaApplied {
+		ops := make([]fga.UpdateResourceTypeOpts, 0, len(s.fixtures.resourceTypes))
+		for _, rt := range s.fixtures.resourceTypes {
+			ops = append(ops, fga.UpdateResourceTypeOpts(rt))
+		}
+		_, err := client.BatchUpdateResourceTypes(context.Background(), ops)
+		if err != nil {
+			printer.PrintWarning(fmt.Sprintf("error restoring resource types for suite %s: %v", s.Name, err))
+		}
+	}
+	s.fixtures = fgaTestSuiteFixtures{}
+}
+
+func (a fgaTestAssertion) name(index int) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	if a.Check != nil {
+		return fmt.Sprintf("%s %s %s", a.Check.Subject, a.Check.Relation, a.Check.Resource)
+	}
+	if a.Query != "" {
+		return a.Query
+	}
+	return fmt.Sprintf("test %d", index+1)
+}
+
+func (a fgaTestAssertion) run(client *fga.Client, index int, warrantToken string) fgaTestResult {
+	start := time.Now()
+	result := fgaTestResult{Name: a.name(index)}
+	var err error
+	if a.Check != nil {
+		result.Passed, result.Message, err = a.runCheck(client, warrantToken)
+	} else {
+		result.Passed, result.Message, err = a.runQuery(client, warrantToken)
+	}
+	if err != nil {
+		result.Passed = false
+		result.Message = err.Error()
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (a fgaTestAssertion) runCheck(client *fga.Client, warrantToken string) (bool, string, error) {
+	expect, ok := a.Expect.(bool)
+	if !ok {
+		return false, "", errors.New("check expects true or false")
+	}
+	warrantCheck, err := a.Check.warrantCheck()
+	if err != nil {
+		return false, "", err
+	}
+	response, err := client.Check(context.Background(), fga.CheckOpts{
+		Checks:       []fga.WarrantCheck{warrantCheck},
+		WarrantToken: warrantToken,
+	})
+	if err != nil {
+		return false, "", errors.Errorf("error evaluating check: %v", err)
+	}
+
+	res := newCheckResult(warrantCheck, response, &expect)
+	if *res.Passed {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("expected %t, got %s", expect, res.Result), nil
+}
+
+func (a fgaTestAssertion) runQuery(client *fga.Client, warrantToken string) (bool, string, error) {
+	expectList, ok := a.Expect.([]any)
+	if !ok && a.Expect != nil {
+		return false, "", errors.New("query expects a list of resources")
+	}
+	var expected []string
+	for _, item := range expectList {
+		expected = append(expected, fmt.Sprint(item))
+	}
+
+	var actual []string
+	_, err := list.Walk(
+		list.Options{All: true},
+		func(after string) ([]fga.QueryResult, common.ListMetadata, error) {
+			res, err := client.Query(context.Background(), fga.QueryOpts{
+				Query:        a.Query,
+				Context:      a.Context,
+				Limit:        list.MaxPageSize,
+				After:        after,
+				WarrantToken: warrantToken,
+			})
+			return res.Data, res.ListMetadata, err
+		},
+		func(queryResult fga.QueryResult) error {
+			actual = append(actual, fmt.Sprintf("%s:%s", queryResult.ResourceType, queryResult.ResourceId))
+			return nil
+		},
+	)
+	if err != nil {
+		return false, "", errors.Errorf("error performing query: %v", err)
+	}
+
+	slices.Sort(expected)
+	slices.Sort(actual)
+	actual = slices.Compact(actual)
+	if slices.Equal(expected, actual) {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("expected [%s], got [%s]", strings.Join(expected, ", "), strings.Join(actual, ", ")), nil
+}
+
+// writeFgaTestReport writes the report to a file, or stdout if no file is given
+func writeFgaTestReport(reporter string, reportFile string, suites []fgaTestSuiteResult) error {
+	var out io.Writer = os.Stdout
+	if reportFile != "" {
+		file, err := os.Create(reportFile)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if reporter == ReporterJunit {
+		return writeJunitReport(out, suites)
+	}
+	return writeTapReport(out, suites)
+}
+
+func writeTapReport(w io.Writer, suites []fgaTestSuiteResult) error {
+	total := 0
+	for _, suite := range suites {
+		total += len(suite.Results)
+	}
+
+	var b strings.Builder
+	b.WriteString("TAP version 13\n")
+	fmt.Fprintf(&b, "1..%d\n", total)
+	n := 0
+	for _, suite := range suites {
+		fmt.Fprintf(&b, "# %s\n", suite.Name)
+		for _, result := range suite.Results {
+			n++
+			if result.Passed {
+				fmt.Fprintf(&b, "ok %d - %s\n", n, result.Name)
+				continue
+			}
+			fmt.Fprintf(&b, "not ok %d - %s\n", n, result.Name)
+			fmt.Fprintf(&b, "  ---\n  message: %q\n  ...\n", result.Message)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJunitReport(w io.Writer, suites []fgaTestSuiteResult) error {
+	report := junitTestSuites{}
+	for _, suite := range suites {
+		junitSuite := junitTestSuite{
+			Name:  suite.Name,
+			Tests: len(suite.Results),
+			Time:  fmt.Sprintf("%.3f", suite.Duration.Seconds()),
+		}
+		for _, result := range suite.Results {
+			testCase := junitTestCase{
+				Name:      result.Name,
+				Classname: suite.Name,
+				Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+			}
+			if !result.Passed {
+				junitSuite.Failures++
+				testCase.Failure = &junitFailure{Message: result.Message, Text: result.Message}
+			}
+			junitSuite.Cases = append(junitSuite.Cases, testCase)
+		}
+		report.Tests += junitSuite.Tests
+		report.Failures += junitSuite.Failures
+		report.Suites = append(report.Suites, junitSuite)
+	}
+
+	bytes, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, bytes)
+	return err
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/workos/workos-go/v4/pkg/fga"
+)
+
+func TestFgaTestSuiteSetupErrorWithoutTests(t *testing.T) {
+	suite := fgaTestSuite{
+		Name:       "schema only",
+		SchemaFile: "missing.txt",
+		path:       filepath.Join(t.TempDir(), "suite.yaml"),
+	}
+
+	result := suite.run(&fga.Client{}, false)
+	if len(result.Results) != 1 {
+		t.Fatalf("expected a single setup result, got %d", len(result.Results))
+	}
+	setup := result.Results[0]
+	if setup.Name != "setup" || setup.Passed || setup.Message == "" {
+		t.Errorf("expected a failed setup result with the error, got %+v", setup)
+	}
+}
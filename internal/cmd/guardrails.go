@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-cli/internal/printer"
+)
+
+const (
+	FlagYes = "yes"
+
+	// AnnotationGuarded marks commands that change data in the environment they run against
+	AnnotationGuarded = "workos_guarded"
+)
+
+// guard marks commands that change data. Running them against a Production environment
+// shows a banner and requires typing the environment's name to confirm, or passing --yes.
+// guard and addPlanFlags both register --yes only if it's missing, so they can be called in any order.
+//
+// Commands that only read data or create short-lived artifacts aren't guarded: lists and gets,
+// fga check and query, portal link, sso test-login, auditlog export and events tail.
+// sync confirms its --to environment itself since that's not the active environment.
+func guard(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[AnnotationGuarded] = "true"
+		addYesFlag(cmd.Flags(), "run against a production environment without asking for confirmation")
+	}
+}
+
+// addYesFlag registers --yes/-y unless another init function already did
+func addYesFlag(flags *pflag.FlagSet, usage string) {
+	if flags.Lookup(FlagYes) == nil {
+		flags.BoolP(FlagYes, "y", false, usage)
+	}
+}
+
+// guardActiveEnvironment runs before every command and confirms guarded commands
+// that run against a Production environment
+func guardActiveEnvironment(cmd *cobra.Command, args []string) error {
+	if cmd.Annotations[AnnotationGuarded] == "" {
+		return nil
+	}
+	return confirmEnvironment(cmd, activeEnvName, activeEnv)
+}
+
+// confirmEnvironment asks for confirmation before cmd changes data in a Production environment.
+// Dry runs, which don't change anything, only show the banner.
+func confirmEnvironment(cmd *cobra.Command, name string, env config.Environment) error {
+	if env.Type != EnvironmentTypeProduction {
+		return nil
+	}
+	printer.PrintBanner(fmt.Sprintf("%s: %s", EnvironmentTypeProduction, name))
+
+	if dryRun, err := cmd.Flags().GetBool("dry-run"); err == nil && dryRun {
+		return nil
+	}
+	yes, err := cmd.Flags().GetBool(FlagYes)
+	if err != nil {
+		return errors.New("invalid yes flag")
+	}
+	if yes {
+		return nil
+	}
+	if !isInteractive() {
+		return errors.Errorf("refusing to run %s against production environment %s without --yes", cmd.CommandPath(), name)
+	}
+
+	var typed string
+	err = huh.NewInput().
+		Title(fmt.Sprintf("%s changes data in production environment %s. Type %s to continue.", cmd.CommandPath(), name, name)).
+		Value(&typed).
+		Run()
+	if err != nil {
+		return err
+	}
+	if typed != name {
+		return errors.New("environment name did not match, no changes applied")
+	}
+	return nil
+}
+
+// isInteractive reports whether stdin is a terminal that can answer prompts
+func isInteractive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"github.com/workos/workos-cli/internal/printer"
 	"regexp"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	"github.com/spf13/cobra"
@@ -13,14 +15,19 @@ import (
 func init() {
 	rootCmd.AddCommand(initCmd)
 	initCmd.Flags().String(FlagEndpoint, "", "Override the API endpoint")
+	initCmd.Flags().String(FlagSecretStore, config.SecretStoreKeyring, "Where to store the API key ("+strings.Join(config.SecretStores, ", ")+")")
+	addYesFlag(initCmd.Flags(), "Replace an existing environment with the same name without asking")
 }
 
 var initCmd = &cobra.Command{
-	Use:     "init",
-	Short:   "Initialize the CLI",
-	Long:    "Initialize the CLI by configuring an API key for it to use.",
-	Example: "workos init",
-	Args:    cobra.NoArgs,
+	Use:   "init [name] [apiKey] [endpoint]",
+	Short: "Initialize the CLI",
+	Long: "Initialize the CLI by configuring an API key for it to use. The environment is added to any existing " +
+		"configuration and made the active environment. Replacing an environment with the same name asks for confirmation, " +
+		"and without a terminal to ask in it requires --yes. Use 'workos config restore' to undo the change.",
+	Example: `workos init
+workos init ci "$WORKOS_API_KEY" --yes`,
+	Args: cobra.RangeArgs(0, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var (
 			name     string
@@ -33,6 +40,14 @@ var initCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		secretStore, err := getSecretStore(cmd)
+		if err != nil {
+			return err
+		}
+		yes, err := cmd.Flags().GetBool(FlagYes)
+		if err != nil {
+			return err
+		}
 
 		if len(args) > 0 {
 			name = args[0]
@@ -82,25 +97,55 @@ var initCmd = &cobra.Command{
 			}
 		}
 
-		printer.PrintMsg("creating ~/.workos.json")
-		envMap := make(map[string]config.Environment)
-		envMap[name] = config.Environment{
+		cfg := cmdConfig
+		if _, ok := cfg.Environments[name]; ok && !yes {
+			if !isInteractive() {
+				return fmt.Errorf("environment %s already exists, use --yes to replace it", name)
+			}
+			replace := false
+			err = huh.NewConfirm().
+				Title(fmt.Sprintf("Environment %s already exists. Replace it?", name)).
+				Value(&replace).
+				Run()
+			if err != nil {
+				return err
+			}
+			if !replace {
+				return errors.New("environment not replaced, no changes applied")
+			}
+		}
+
+		path, err := config.FilePath()
+		if err != nil {
+			return err
+		}
+		printer.PrintMsg(fmt.Sprintf("updating %s", path))
+		env := config.Environment{
 			ApiKey:   apiKey,
 			Name:     name,
 			Type:     envType,
 			Endpoint: endpoint,
 		}
-		newConfig := config.Config{
-			ActiveEnvironment: name,
-			Environments:      envMap,
+		err = env.StoreApiKey(secretStore)
+		if err != nil {
+			return err
 		}
+		if cfg.Environments == nil {
+			cfg.Environments = make(map[string]config.Environment)
+		}
+		previous, replaced := cfg.Environments[name]
+		cfg.Environments[name] = env
+		cfg.ActiveEnvironment = name
 
-		err = newConfig.Write()
+		err = cfg.Write()
 		if err != nil {
 			return err
 		}
+		if replaced {
+			deleteReplacedApiKey(previous, env)
+		}
 
-		printer.PrintMsg("WorkOS CLI initialized")
+		printer.PrintResult("WorkOS CLI initialized", newEnvironmentSummary(cfg, name))
 		return nil
 	},
 }
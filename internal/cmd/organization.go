@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/workos/workos-go/v4/pkg/common"
 	"github.com/workos/workos-go/v4/pkg/organizations"
 )
 
@@ -22,12 +23,10 @@ func init() {
 	orgCmd.AddCommand(getOrgCmd)
 	orgCmd.AddCommand(listOrgCmd)
 	orgCmd.AddCommand(deleteOrgCmd)
+	guard(createOrgCmd, updateOrgCmd, deleteOrgCmd)
 	rootCmd.AddCommand(orgCmd)
 	listOrgCmd.Flags().String(FlagDomain, "", "Filter by domain")
-	listOrgCmd.Flags().String(list.FlagAfter, "", "Cursor for results after a specific item")
-	listOrgCmd.Flags().String(list.FlagBefore, "", "Cursor for results before a specific item")
-	listOrgCmd.Flags().Int(list.FlagLimit, 0, "Limit the number of results")
-	listOrgCmd.Flags().String(list.FlagOrder, "", "Order of results (asc or desc)")
+	list.AddFlags(listOrgCmd.Flags())
 }
 
 var orgCmd = &cobra.Command{
@@ -43,6 +42,7 @@ var createOrgCmd = &cobra.Command{
 	Example: "workos organization create FooCorp foo-corp.com:pending",
 	Args:    cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		name := args[0]
 		var domainData []organizations.OrganizationDomainData
 
@@ -60,7 +60,7 @@ var createOrgCmd = &cobra.Command{
 			})
 		}
 
-		org, err := organizations.CreateOrganization(
+		org, err := clients.organizations.CreateOrganization(
 			context.Background(),
 			organizations.CreateOrganizationOpts{
 				Name:       name,
@@ -72,7 +72,7 @@ var createOrgCmd = &cobra.Command{
 		}
 
 		printer.PrintMsg("Created organization")
-		printer.PrintJson(org)
+		printer.Print(org)
 		return nil
 	},
 }
@@ -84,6 +84,7 @@ var updateOrgCmd = &cobra.Command{
 	Example: "workos organization update org_01EHZNVPK3SFK441A1RGBFSHRT FooCorp foo-corp.com pending",
 	Args:    cobra.RangeArgs(2, 4),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		organizationId := args[0]
 		name := args[1]
 		var domainData []organizations.OrganizationDomainData
@@ -108,7 +109,7 @@ var updateOrgCmd = &cobra.Command{
 			orgOpts.DomainData = domainData
 		}
 
-		org, err := organizations.UpdateOrganization(
+		org, err := clients.organizations.UpdateOrganization(
 			context.Background(),
 			orgOpts,
 		)
@@ -117,7 +118,7 @@ var updateOrgCmd = &cobra.Command{
 		}
 
 		printer.PrintMsg("Updated organization")
-		printer.PrintJson(org)
+		printer.Print(org)
 		return nil
 	},
 }
@@ -128,8 +129,9 @@ var getOrgCmd = &cobra.Command{
 	Example: `workos organization get <organization_id>`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		organizationId := args[0]
-		org, err := organizations.GetOrganization(
+		org, err := clients.organizations.GetOrganization(
 			context.Background(),
 			organizations.GetOrganizationOpts{
 				Organization: organizationId,
@@ -139,7 +141,7 @@ var getOrgCmd = &cobra.Command{
 			return errors.Wrap(err, "error getting organization")
 		}
 
-		printer.PrintJson(org)
+		printer.Print(org)
 		return nil
 	},
 }
@@ -147,13 +149,15 @@ var getOrgCmd = &cobra.Command{
 var listOrgCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List organizations with optional filters",
-	Long:  "List organizations, optionally filtering by domain, limit, before/after cursor, and order (asc/desc).",
+	Long:  "List organizations, optionally filtering by domain, limit, before/after cursor, and order (asc/desc). Use --all to fetch every page.",
 	Example: `workos organization list --domain foo-corp.com --limit 10 --before cursor --order desc
-workos organization list --domain foo-corp.com --after cursor --order asc`,
+workos organization list --domain foo-corp.com --after cursor --order asc
+workos organization list --all --max-items 5000 -o csv`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		after, err := cmd.Flags().GetString(list.FlagAfter)
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
 		if err != nil {
-			return errors.New("invalid after flag")
+			return err
 		}
 		before, err := cmd.Flags().GetString(list.FlagBefore)
 		if err != nil {
@@ -163,10 +167,6 @@ workos organization list --domain foo-corp.com --after cursor --order asc`,
 		if err != nil {
 			return errors.New("invalid domain flag")
 		}
-		limit, err := cmd.Flags().GetInt(list.FlagLimit)
-		if err != nil {
-			return errors.New("invalid limit flag")
-		}
 		order, err := cmd.Flags().GetString(list.FlagOrder)
 		if err != nil {
 			return errors.New("invalid order flag")
@@ -177,41 +177,45 @@ workos organization list --domain foo-corp.com --after cursor --order asc`,
 			domains = strings.Fields(domain)
 		}
 
-		orgs, err := organizations.ListOrganizations(
-			context.Background(),
-			organizations.ListOrganizationsOpts{
-				Domains: domains,
-				Limit:   limit,
-				Before:  before,
-				After:   after,
-				Order:   organizations.Order(order),
+		p := printer.NewListPrinter(120, "ID", "Name", "Domains")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]organizations.Organization, common.ListMetadata, error) {
+				orgs, err := clients.organizations.ListOrganizations(
+					context.Background(),
+					organizations.ListOrganizationsOpts{
+						Domains: domains,
+						Limit:   limit,
+						Before:  before,
+						After:   after,
+						Order:   organizations.Order(order),
+					},
+				)
+				return orgs.Data, orgs.ListMetadata, err
+			},
+			func(org organizations.Organization) error {
+				var domains []string
+				for _, d := range org.Domains {
+					domains = append(domains, d.Domain)
+				}
+
+				p.Add(
+					org,
+					org.ID,
+					org.Name,
+					strings.Join(domains, ", "),
+				)
+				return nil
 			},
 		)
 		if err != nil {
 			return errors.Wrap(err, "error listing organizations")
 		}
 
-		tbl := printer.NewTable(120).Headers(
-			printer.TableHeader("ID"),
-			printer.TableHeader("Name"),
-			printer.TableHeader("Domains"),
-		)
-		for _, org := range orgs.Data {
-			var domains []string
-			for _, d := range org.Domains {
-				domains = append(domains, d.Domain)
-			}
-
-			tbl.Row(
-				org.ID,
-				org.Name,
-				strings.Join(domains, ", "),
-			)
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
 		}
-
-		printer.PrintMsg(tbl.Render())
-		printer.PrintMsg(fmt.Sprintf("Before: %s", orgs.ListMetadata.Before))
-		printer.PrintMsg(fmt.Sprintf("After: %s", orgs.ListMetadata.After))
+		p.Flush()
 		return nil
 	},
 }
@@ -223,8 +227,9 @@ var deleteOrgCmd = &cobra.Command{
 	Example: `workos organization delete <organization_id>`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
 		organizationId := args[0]
-		err := organizations.DeleteOrganization(
+		err := clients.organizations.DeleteOrganization(
 			context.Background(),
 			organizations.DeleteOrganizationOpts{
 				Organization: organizationId,
@@ -234,7 +239,7 @@ var deleteOrgCmd = &cobra.Command{
 		if err != nil {
 			return errors.Wrap(err, "error deleting organization")
 		}
-		printer.PrintMsg(fmt.Sprintf("Deleted organization %s", organizationId))
+		printer.PrintResult(fmt.Sprintf("Deleted organization %s", organizationId), deletedResult{ID: organizationId, Deleted: true})
 		return nil
 	},
 }
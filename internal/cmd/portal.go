@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/portal"
+)
+
+const (
+	FlagCopy       = "copy"
+	FlagIntent     = "intent"
+	FlagOpen       = "open"
+	FlagReturnUrl  = "return-url"
+	FlagSuccessUrl = "success-url"
+)
+
+var portalIntents = []string{
+	string(portal.SSO),
+	string(portal.DSync),
+	string(portal.AuditLogs),
+	string(portal.LogStreams),
+	string(portal.DomainVerification),
+}
+
+func init() {
+	linkCmd.Flags().String(FlagIntent, "", "Portal intent ("+strings.Join(portalIntents, ", ")+")")
+	linkCmd.Flags().String(FlagReturnUrl, "", "URL to send the admin to when they leave the portal")
+	linkCmd.Flags().String(FlagSuccessUrl, "", "URL to send the admin to when they finish setup")
+	linkCmd.Flags().Bool(FlagOpen, false, "Open the link in the browser")
+	linkCmd.Flags().Bool(FlagCopy, false, "Copy the link to the clipboard")
+	_ = linkCmd.MarkFlagRequired(FlagIntent)
+	portalCmd.AddCommand(linkCmd)
+	rootCmd.AddCommand(portalCmd)
+}
+
+var portalCmd = &cobra.Command{
+	Use:   "portal",
+	Short: "Generate Admin Portal links.",
+	Long:  "Generate Admin Portal links that let an organization's IT admin set up SSO, Directory Sync, Audit Logs and more.",
+}
+
+type portalLinkResult struct {
+	Organization string `json:"organization_id"`
+	Intent       string `json:"intent"`
+	Link         string `json:"link"`
+}
+
+var linkCmd = &cobra.Command{
+	Use:     "link <organization_id>",
+	Short:   "Generate an Admin Portal link",
+	Long:    "Generate a short-lived Admin Portal link for an organization. Links expire five minutes after they are generated.",
+	Example: "workos portal link org_01EHZNVPK3SFK441A1RGBFSHRT --intent sso --return-url https://example.com/settings --copy",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		intent, err := cmd.Flags().GetString(FlagIntent)
+		if err != nil {
+			return errors.New("invalid intent flag")
+		}
+		if !slices.Contains(portalIntents, intent) {
+			return errors.Errorf("invalid intent: %s (must be one of %s)", intent, strings.Join(portalIntents, ", "))
+		}
+		returnUrl, err := cmd.Flags().GetString(FlagReturnUrl)
+		if err != nil {
+			return errors.New("invalid return-url flag")
+		}
+		successUrl, err := cmd.Flags().GetString(FlagSuccessUrl)
+		if err != nil {
+			return errors.New("invalid success-url flag")
+		}
+		open, err := cmd.Flags().GetBool(FlagOpen)
+		if err != nil {
+			return errors.New("invalid open flag")
+		}
+		copyLink, err := cmd.Flags().GetBool(FlagCopy)
+		if err != nil {
+			return errors.New("invalid copy flag")
+		}
+
+		link, err := clients.portal.GenerateLink(
+			context.Background(),
+			portal.GenerateLinkOpts{
+				Organization: args[0],
+				Intent:       portal.GenerateLinkIntent(intent),
+				ReturnURL:    returnUrl,
+				SuccessURL:   successUrl,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error generating portal link")
+		}
+
+		if copyLink {
+			if err := clipboard.WriteAll(link); err != nil {
+				printer.PrintWarning(fmt.Sprintf("unable to copy link to clipboard: %v", err))
+			} else {
+				printer.PrintMsg(printer.GreenText(printer.Checkmark, "Copied link to clipboard"))
+			}
+		}
+		if open {
+			if err := openBrowser(link); err != nil {
+				printer.PrintWarning(fmt.Sprintf("unable to open browser: %v", err))
+			}
+		}
+
+		printer.PrintResult(link, portalLinkResult{Organization: args[0], Intent: intent, Link: link})
+		return nil
+	},
+}
@@ -1,26 +1,62 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"io"
 	"log"
+	"os"
+	"strings"
 
+	"github.com/charmbracelet/huh"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/api"
 	"github.com/workos/workos-cli/internal/config"
-	"github.com/workos/workos-go/v4/pkg/fga"
-	"github.com/workos/workos-go/v4/pkg/organizations"
+	"github.com/workos/workos-cli/internal/printer"
+)
+
+const (
+	FlagApiKey = "api-key"
+	FlagEnv    = "env"
+	FlagOutput = "output"
 )
 
 var cmdConfig *config.Config
+var outputFormat string
+
+// activeEnvName and activeEnv are the environment commands run against: the configured active
+// environment or the one selected with --env, with the --api-key and --endpoint overrides applied.
+// Overrides only apply to a single invocation and are never written to the config file.
+var activeEnvName string
+var activeEnv config.Environment
+
+var envOverride string
+var apiKeyOverride string
+var endpointOverride string
+
+// deletedResult is printed by delete commands when a machine-readable output format is selected
+type deletedResult struct {
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "workos",
 	Short: "WorkOS Command Line Interface (CLI)",
 	Long:  "The WorkOS CLI is a tool to interact with WorkOS APIs via the command line.",
+
+	PersistentPreRunE: guardActiveEnvironment,
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
+	config.PassphrasePrompt = promptSecretsPassphrase
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, FlagOutput, "o", printer.FormatTable, "Output format ("+strings.Join(printer.Formats, ", ")+")")
+	rootCmd.PersistentFlags().StringVar(&envOverride, FlagEnv, "", "Environment to run the command against instead of the active environment")
+	rootCmd.PersistentFlags().StringVar(&apiKeyOverride, FlagApiKey, "", "API key to use instead of the environment's")
+	rootCmd.PersistentFlags().StringVar(&endpointOverride, FlagEndpoint, "", "API endpoint to use instead of the environment's")
 }
 
 func SetVersion(version string) {
@@ -34,24 +70,79 @@ func Execute() {
 }
 
 func GetConfigOrExit() *config.Config {
-	if cmdConfig.ActiveEnvironment == "" {
+	if activeEnvName == "" {
 		log.Fatal("no active environment configured. Run 'workos init'")
 	}
 	if len(cmdConfig.Environments) == 0 {
 		log.Fatal("no environments configured. Run 'workos init'")
 	}
-	if _, ok := cmdConfig.Environments[cmdConfig.ActiveEnvironment]; !ok {
+	if _, ok := cmdConfig.Environments[activeEnvName]; !ok {
 		log.Fatal("configured active environment is invalid. Run 'workos init'")
 	}
 	return cmdConfig
 }
 
+// newApiClient returns a client for API endpoints not covered by workos-go
+func newApiClient() *api.Client {
+	GetConfigOrExit()
+	return api.NewClient(activeEnv.ApiKey, activeEnv.Endpoint)
+}
+
+// readFileOrStdin reads the named file, or stdin if the name is empty or "-"
+func readFileOrStdin(name string) ([]byte, error) {
+	if name == "" || name == "-" {
+		return io.ReadAll(bufio.NewReader(os.Stdin))
+	}
+	return os.ReadFile(name)
+}
+
 func initConfig() {
+	cobra.CheckErr(printer.SetFormat(outputFormat))
 	cmdConfig = config.LoadConfig()
-	organizations.SetAPIKey(cmdConfig.Environments[cmdConfig.ActiveEnvironment].ApiKey)
-	fga.SetAPIKey(cmdConfig.Environments[cmdConfig.ActiveEnvironment].ApiKey)
-	if cmdConfig.Environments[cmdConfig.ActiveEnvironment].Endpoint != "" {
-		organizations.DefaultClient.Endpoint = cmdConfig.Environments[cmdConfig.ActiveEnvironment].Endpoint
-		fga.DefaultClient.Endpoint = cmdConfig.Environments[cmdConfig.ActiveEnvironment].Endpoint
+	for _, warning := range cmdConfig.Warnings() {
+		printer.PrintWarning(warning)
+	}
+
+	activeEnvName = cmdConfig.ActiveEnvironment
+	if envOverride != "" {
+		if _, ok := cmdConfig.Environments[envOverride]; !ok {
+			cobra.CheckErr(errors.Errorf("environment %s is not configured", envOverride))
+		}
+		activeEnvName = envOverride
+	}
+
+	// Resolve the environment's API key if it's kept in a secret store. There's
+	// no need to read it when it's overridden.
+	if env, ok := cmdConfig.Environments[activeEnvName]; ok && apiKeyOverride == "" {
+		apiKey, err := env.ResolveApiKey()
+		if err != nil {
+			printer.PrintWarning(err.Error())
+		}
+		env.ApiKey = apiKey
+		cmdConfig.Environments[activeEnvName] = env
 	}
+
+	activeEnv = cmdConfig.Environments[activeEnvName]
+	if apiKeyOverride != "" {
+		activeEnv.ApiKey = apiKeyOverride
+	}
+	if endpointOverride != "" {
+		activeEnv.Endpoint = endpointOverride
+	}
+}
+
+func promptSecretsPassphrase() (string, error) {
+	var passphrase string
+	err := huh.NewInput().
+		Title("Enter the passphrase for ~/" + config.SecretsFileName).
+		EchoMode(huh.EchoModePassword).
+		Value(&passphrase).
+		Validate(func(s string) error {
+			if s == "" {
+				return errors.New("passphrase must not be empty")
+			}
+			return nil
+		}).
+		Run()
+	return passphrase, err
 }
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
+	"github.com/workos/workos-go/v4/pkg/sso"
+)
+
+const (
+	FlagClientId       = "client-id"
+	FlagConnectionType = "connection-type"
+	FlagNoBrowser      = "no-browser"
+	FlagPort           = "port"
+	FlagTimeout        = "timeout"
+
+	EnvVarClientId = config.EnvVarPrefix + "_CLIENT_ID"
+)
+
+func init() {
+	listConnectionsCmd.Flags().String(FlagOrganization, "", "Filter by organization id")
+	listConnectionsCmd.Flags().String(FlagConnectionType, "", "Filter by connection type (e.g. OktaSAML, GoogleOAuth)")
+	listConnectionsCmd.Flags().String(FlagDomain, "", "Filter by domain")
+	list.AddFlags(listConnectionsCmd.Flags())
+	ssoCmd.AddCommand(listConnectionsCmd)
+	ssoCmd.AddCommand(getConnectionCmd)
+	ssoCmd.AddCommand(deleteConnectionCmd)
+	guard(deleteConnectionCmd)
+	testLoginCmd.Flags().String(FlagClientId, "", "Client ID of the environment (defaults to $"+EnvVarClientId+")")
+	testLoginCmd.Flags().Int(FlagPort, 8000, "Port for the local callback server. http://127.0.0.1:<port>/callback must be a configured redirect URI")
+	testLoginCmd.Flags().Bool(FlagNoBrowser, false, "Print the authorization URL without opening a browser")
+	testLoginCmd.Flags().Duration(FlagTimeout, 5*time.Minute, "How long to wait for the login to complete")
+	ssoCmd.AddCommand(testLoginCmd)
+	rootCmd.AddCommand(ssoCmd)
+}
+
+var ssoCmd = &cobra.Command{
+	Use:   "sso",
+	Short: "Manage SSO connections and test logins.",
+	Long:  "List, inspect and delete SSO connections and run a test login against a connection's identity provider.",
+}
+
+var listConnectionsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List connections with optional filters",
+	Long:    "List SSO connections, optionally filtering by organization, connection type or domain.",
+	Example: "workos sso list --organization org_01EHZNVPK3SFK441A1RGBFSHRT --connection-type OktaSAML",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+		connectionType, err := cmd.Flags().GetString(FlagConnectionType)
+		if err != nil {
+			return errors.New("invalid connection-type flag")
+		}
+		domain, err := cmd.Flags().GetString(FlagDomain)
+		if err != nil {
+			return errors.New("invalid domain flag")
+		}
+
+		p := printer.NewListPrinter(120, "ID", "Name", "Type", "State", "Organization ID", "Domains")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]sso.Connection, common.ListMetadata, error) {
+				connections, err := clients.sso.ListConnections(
+					context.Background(),
+					sso.ListConnectionsOpts{
+						ConnectionType: sso.ConnectionType(connectionType),
+						OrganizationID: organization,
+						Domain:         domain,
+						Limit:          limit,
+						Before:         before,
+						After:          after,
+						Order:          sso.Order(order),
+					},
+				)
+				return connections.Data, connections.ListMetadata, err
+			},
+			func(connection sso.Connection) error {
+				var domains []string
+				for _, d := range connection.Domains {
+					domains = append(domains, d.Domain)
+				}
+
+				p.Add(
+					connection,
+					connection.ID,
+					connection.Name,
+					string(connection.ConnectionType),
+					string(connection.State),
+					connection.OrganizationID,
+					strings.Join(domains, ", "),
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing connections")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var getConnectionCmd = &cobra.Command{
+	Use:     "get <connection_id>",
+	Short:   "Get a connection",
+	Long:    "Get an SSO connection by id. Find the connection's id by listing your connections.",
+	Example: "workos sso get conn_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		connection, err := clients.sso.GetConnection(
+			context.Background(),
+			sso.GetConnectionOpts{
+				Connection: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error getting connection")
+		}
+
+		printer.Print(connection)
+		return nil
+	},
+}
+
+var deleteConnectionCmd = &cobra.Command{
+	Use:     "delete <connection_id>",
+	Short:   "Delete a connection",
+	Long:    "Delete an SSO connection by id. Users will no longer be able to sign in through it.",
+	Example: "workos sso delete conn_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		connectionId := args[0]
+		err := clients.sso.DeleteConnection(
+			context.Background(),
+			sso.DeleteConnectionOpts{
+				Connection: connectionId,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error deleting connection")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Deleted connection %s", connectionId), deletedResult{ID: connectionId, Deleted: true})
+		return nil
+	},
+}
+
+var testLoginCmd = &cobra.Command{
+	Use:   "test-login <connection_id>",
+	Short: "Test a login through a connection",
+	Long: "Run an SSO login through a connection using a local callback server and print the resulting profile. " +
+		"The callback URL (http://127.0.0.1:<port>/callback) must be configured as a redirect URI for the environment.",
+	Example: "workos sso test-login conn_01E4ZCR3C56J083X43JQXF3JK5 --client-id client_123 --port 8000",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		clientId, err := cmd.Flags().GetString(FlagClientId)
+		if err != nil {
+			return errors.New("invalid client-id flag")
+		}
+		if clientId == "" {
+			clientId = os.Getenv(EnvVarClientId)
+		}
+		if clientId == "" {
+			return errors.Errorf("a client ID is required (use --%s or set %s)", FlagClientId, EnvVarClientId)
+		}
+		port, err := cmd.Flags().GetInt(FlagPort)
+		if err != nil {
+			return errors.New("invalid port flag")
+		}
+		noBrowser, err := cmd.Flags().GetBool(FlagNoBrowser)
+		if err != nil {
+			return errors.New("invalid no-browser flag")
+		}
+		timeout, err := cmd.Flags().GetDuration(FlagTimeout)
+		if err != nil {
+			return errors.New("invalid timeout flag")
+		}
+
+		stateBytes := make([]byte, 16)
+		_, err = rand.Read(stateBytes)
+		if err != nil {
+			return err
+		}
+		state := hex.EncodeToString(stateBytes)
+
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			return errors.Wrap(err, "error starting callback server")
+		}
+		redirectUri := fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+		clients.sso.ClientID = clientId
+		authorizationUrl, err := clients.sso.GetAuthorizationURL(sso.GetAuthorizationURLOpts{
+			Connection:  args[0],
+			RedirectURI: redirectUri,
+			State:       state,
+		})
+		if err != nil {
+			_ = listener.Close()
+			return errors.Wrap(err, "error building authorization url")
+		}
+
+		// Buffered so that a duplicate callback never blocks the handler
+		type callbackResult struct {
+			code string
+			err  error
+		}
+		results := make(chan callbackResult, 1)
+		mux := http.NewServeMux()
+		mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			// Requests without the state, a code or an error, e.g. from a browser prefetch or a tab
+			// left open by an earlier run, are rejected without ending the wait for the real callback
+			switch {
+			case query.Get("state") != state:
+				http.Error(w, "Invalid state. You can close this window.", http.StatusBadRequest)
+				return
+			case query.Get("code") == "" && query.Get("error") == "":
+				http.Error(w, "Missing authorization code. You can close this window.", http.StatusBadRequest)
+				return
+			}
+			var result callbackResult
+			if query.Get("error") != "" {
+				http.Error(w, "Login failed. You can close this window.", http.StatusBadRequest)
+				result.err = errors.Errorf("login failed: %s %s", query.Get("error"), query.Get("error_description"))
+			} else {
+				_, _ = fmt.Fprintln(w, "Login complete. You can close this window and return to the terminal.")
+				result.code = query.Get("code")
+			}
+			select {
+			case results <- result:
+			default:
+			}
+		})
+		server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			_ = server.Serve(listener)
+		}()
+		defer server.Close()
+
+		printer.PrintMsg(fmt.Sprintf("Listening for the callback on %s", redirectUri))
+		printer.PrintMsg(fmt.Sprintf("Open this URL to log in:\n%s", authorizationUrl.String()))
+		if !noBrowser {
+			if err := openBrowser(authorizationUrl.String()); err != nil {
+				printer.PrintWarning(fmt.Sprintf("unable to open browser: %v", err))
+			}
+		}
+
+		var result callbackResult
+		select {
+		case result = <-results:
+			if result.err != nil {
+				return result.err
+			}
+		case <-time.After(timeout):
+			return errors.New("timed out waiting for the login to complete")
+		}
+
+		profileAndToken, err := clients.sso.GetProfileAndToken(
+			context.Background(),
+			sso.GetProfileAndTokenOpts{
+				Code: result.code,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error exchanging authorization code")
+		}
+
+		printer.PrintMsg(printer.GreenText(printer.Checkmark, "Login succeeded"))
+		printer.Print(profileAndToken.Profile)
+		return nil
+	},
+}
@@ -0,0 +1,554 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/config"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
+	"github.com/workos/workos-go/v4/pkg/fga"
+	"github.com/workos/workos-go/v4/pkg/organizations"
+)
+
+const (
+	FlagFrom       = "from"
+	FlagTo         = "to"
+	FlagOnConflict = "on-conflict"
+
+	SyncKindOrganizations = "organizations"
+	SyncKindResourceTypes = "resource-types"
+	SyncKindResources     = "resources"
+	SyncKindWarrants      = "warrants"
+
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+
+	SyncActionCreate = "create"
+	SyncActionUpdate = "update"
+	SyncActionSkip   = "skip"
+)
+
+// syncKinds are the kinds of data sync copies, in the order they are applied.
+// Resource types go before resources and warrants since those depend on them.
+var syncKinds = []string{SyncKindOrganizations, SyncKindResourceTypes, SyncKindResources, SyncKindWarrants}
+
+var conflictModes = []string{ConflictSkip, ConflictOverwrite}
+
+func init() {
+	syncCmd.Flags().String(FlagFrom, "", "Environment to copy data from")
+	syncCmd.Flags().String(FlagTo, "", "Environment to copy data into")
+	syncCmd.Flags().String(FlagOnConflict, ConflictSkip, "What to do with data that exists in both environments but differs ("+strings.Join(conflictModes, ", ")+")")
+	addPlanFlags(syncCmd.Flags())
+	_ = syncCmd.MarkFlagRequired(FlagFrom)
+	_ = syncCmd.MarkFlagRequired(FlagTo)
+	rootCmd.AddCommand(syncCmd)
+}
+
+// envClients holds API clients for a configured environment, which may not be the active one
+type envClients struct {
+	*apiClients
+	name string
+}
+
+func newEnvClients(cfg *config.Config, name string) (*envClients, error) {
+	env, ok := cfg.Environments[name]
+	if !ok {
+		return nil, errors.Errorf("environment %s is not configured", name)
+	}
+	apiKey, err := env.ResolveApiKey()
+	if err != nil {
+		return nil, err
+	}
+	if apiKey == "" {
+		return nil, errors.Errorf("environment %s has no api key", name)
+	}
+	env.ApiKey = apiKey
+	return &envClients{apiClients: clientsFor(env), name: name}, nil
+}
+
+// syncChange is a single change sync makes, or skips, in the target environment
+type syncChange struct {
+	Kind   string `json:"kind"`
+	Key    string `json:"key"`
+	Action string `json:"action"`
+	// Reason explains a skip that --on-conflict doesn't resolve
+	Reason string `json:"reason,omitempty"`
+}
+
+// syncStep is the planned changes for one kind of data along with the function that applies them
+type syncStep struct {
+	changes []syncChange
+	apply   func() error
+}
+
+type syncResult struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	DryRun  bool         `json:"dry_run"`
+	Changes []syncChange `json:"changes"`
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [kinds...]",
+	Short: "Copy data between environments",
+	Long: "Copy data from one configured environment into another, e.g. to seed a local or staging environment. " +
+		"Kinds are " + strings.Join(syncKinds, ", ") + " and default to all of them. " +
+		"Organizations are matched by name and resources by type and id. Organizations whose name isn't unique in either environment are skipped. " +
+		"Nothing is deleted from the target environment: data that only exists there is kept, " +
+		"and data that exists in both environments but differs is skipped or overwritten depending on --" + FlagOnConflict + ". " +
+		"Overwriting a resource type replaces the relations it shares with the source and keeps the relations only the target has.",
+	Example: "workos sync --from staging --to local organizations resource-types --dry-run",
+	Args: func(cmd *cobra.Command, args []string) error {
+		for _, arg := range args {
+			if !slices.Contains(syncKinds, arg) {
+				return errors.Errorf("invalid kind: %s (must be one of %s)", arg, strings.Join(syncKinds, ", "))
+			}
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromName, err := cmd.Flags().GetString(FlagFrom)
+		if err != nil {
+			return errors.New("invalid from flag")
+		}
+		toName, err := cmd.Flags().GetString(FlagTo)
+		if err != nil {
+			return errors.New("invalid to flag")
+		}
+		onConflict, err := cmd.Flags().GetString(FlagOnConflict)
+		if err != nil || !slices.Contains(conflictModes, onConflict) {
+			return errors.New("invalid on-conflict flag")
+		}
+		dryRun, err := cmd.Flags().GetBool("dry-run")
+		if err != nil {
+			return errors.New("invalid dry-run flag")
+		}
+		yes, err := cmd.Flags().GetBool("yes")
+		if err != nil {
+			return errors.New("invalid yes flag")
+		}
+		if fromName == toName {
+			return errors.New("--from and --to must be different environments")
+		}
+
+		kinds := syncKinds
+		if len(args) > 0 {
+			kinds = nil
+			for _, kind := range syncKinds {
+				if slices.Contains(args, kind) {
+					kinds = append(kinds, kind)
+				}
+			}
+		}
+
+		cfg := GetConfigOrExit()
+		from, err := newEnvClients(cfg, fromName)
+		if err != nil {
+			return err
+		}
+		to, err := newEnvClients(cfg, toName)
+		if err != nil {
+			return err
+		}
+
+		overwrite := onConflict == ConflictOverwrite
+		var steps []syncStep
+		for _, kind := range kinds {
+			var step syncStep
+			switch kind {
+			case SyncKindOrganizations:
+				step, err = planOrganizationSync(from, to, overwrite)
+			case SyncKindResourceTypes:
+				step, err = planResourceTypeSync(from, to, overwrite)
+			case SyncKindResources:
+				step, err = planResourceSync(from, to, overwrite)
+			case SyncKindWarrants:
+				step, err = planWarrantSync(from, to)
+			}
+			if err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+
+		result := syncResult{From: fromName, To: toName, DryRun: dryRun, Changes: []syncChange{}}
+		updates := 0
+		for _, step := range steps {
+			for _, change := range step.changes {
+				result.Changes = append(result.Changes, change)
+				if change.Action == SyncActionUpdate {
+					updates++
+				}
+				if printer.IsTable() {
+					printSyncChange(change)
+				}
+			}
+		}
+
+		applied := len(result.Changes)
+		for _, change := range result.Changes {
+			if change.Action == SyncActionSkip {
+				applied--
+			}
+		}
+		if dryRun || applied == 0 {
+			printer.PrintResult(fmt.Sprintf("%d changes to sync from %s to %s", applied, fromName, toName), result)
+			return nil
+		}
+		// The guardrails for production environments apply to the target rather than the active environment
+		if toEnv := cfg.Environments[toName]; toEnv.Type == EnvironmentTypeProduction {
+			err := confirmEnvironment(cmd, toName, toEnv)
+			if err != nil {
+				return err
+			}
+		} else if updates > 0 && !yes {
+			confirmed, err := confirmChanges(fmt.Sprintf("This overwrites %d items in %s. Sync?", updates, toName))
+			if err != nil || !confirmed {
+				return err
+			}
+		}
+
+		for _, step := range steps {
+			err := step.apply()
+			if err != nil {
+				return err
+			}
+		}
+		printer.PrintResult(fmt.Sprintf("Synced %d changes from %s to %s", applied, fromName, toName), result)
+		return nil
+	},
+}
+
+func printSyncChange(change syncChange) {
+	line := fmt.Sprintf("%s %s", change.Kind, change.Key)
+	switch change.Action {
+	case SyncActionCreate:
+		printer.PrintMsg(printer.GreenText("+ " + line))
+	case SyncActionUpdate:
+		printer.PrintMsg(printer.YellowText("~ " + line))
+	case SyncActionSkip:
+		reason := change.Reason
+		if reason == "" {
+			reason = "conflict"
+		}
+		printer.PrintMsg(fmt.Sprintf("= %s (%s, skipped)", line, reason))
+	}
+}
+
+// conflictAction returns the action for an item that exists in both environments but differs
+func conflictAction(overwrite bool) string {
+	if overwrite {
+		return SyncActionUpdate
+	}
+	return SyncActionSkip
+}
+
+func planOrganizationSync(from *envClients, to *envClients, overwrite bool) (syncStep, error) {
+	source, err := listAllOrganizations(from.organizations)
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing organizations in %s: %v", from.name, err)
+	}
+	target, err := listAllOrganizations(to.organizations)
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing organizations in %s: %v", to.name, err)
+	}
+	existing := make(map[string]organizations.Organization, len(target))
+	targetNames := make(map[string]int, len(target))
+	for _, org := range target {
+		existing[org.Name] = org
+		targetNames[org.Name]++
+	}
+	sourceNames := make(map[string]int, len(source))
+	for _, org := range source {
+		sourceNames[org.Name]++
+	}
+
+	var step syncStep
+	var creates []organizations.Organization
+	var updates []organizations.UpdateOrganizationOpts
+	duplicates := map[string]bool{}
+	for _, org := range source {
+		// Organizations are matched by name, so a name that isn't unique can't be matched
+		if sourceNames[org.Name] > 1 || targetNames[org.Name] > 1 {
+			if !duplicates[org.Name] {
+				duplicates[org.Name] = true
+				env := from.name
+				if sourceNames[org.Name] == 1 {
+					env = to.name
+				}
+				step.changes = append(step.changes, syncChange{
+					Kind:   SyncKindOrganizations,
+					Key:    org.Name,
+					Action: SyncActionSkip,
+					Reason: fmt.Sprintf("name isn't unique in %s", env),
+				})
+			}
+			continue
+		}
+		current, ok := existing[org.Name]
+		if !ok {
+			creates = append(creates, org)
+			step.changes = append(step.changes, syncChange{Kind: SyncKindOrganizations, Key: org.Name, Action: SyncActionCreate})
+			continue
+		}
+		if current.AllowProfilesOutsideOrganization == org.AllowProfilesOutsideOrganization &&
+			slices.Equal(organizationDomains(current), organizationDomains(org)) {
+			continue
+		}
+		action := conflictAction(overwrite)
+		step.changes = append(step.changes, syncChange{Kind: SyncKindOrganizations, Key: org.Name, Action: action})
+		if action == SyncActionUpdate {
+			updates = append(updates, organizations.UpdateOrganizationOpts{
+				Organization:                     current.ID,
+				Name:                             org.Name,
+				AllowProfilesOutsideOrganization: org.AllowProfilesOutsideOrganization,
+				DomainData:                       organizationDomainData(org),
+			})
+		}
+	}
+
+	step.apply = func() error {
+		for _, org := range creates {
+			_, err := to.organizations.CreateOrganization(context.Background(), organizations.CreateOrganizationOpts{
+				Name:                             org.Name,
+				AllowProfilesOutsideOrganization: org.AllowProfilesOutsideOrganization,
+				DomainData:                       organizationDomainData(org),
+			})
+			if err != nil {
+				return errors.Errorf("error creating organization %s: %v", org.Name, err)
+			}
+		}
+		for _, opts := range updates {
+			_, err := to.organizations.UpdateOrganization(context.Background(), opts)
+			if err != nil {
+				return errors.Errorf("error updating organization %s: %v", opts.Name, err)
+			}
+		}
+		return nil
+	}
+	return step, nil
+}
+
+func planResourceTypeSync(from *envClients, to *envClients, overwrite bool) (syncStep, error) {
+	source, err := listAllResourceTypes(from.fga)
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing resource types in %s: %v", from.name, err)
+	}
+	target, err := listAllResourceTypes(to.fga)
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing resource types in %s: %v", to.name, err)
+	}
+	sourceOps := make([]fga.UpdateResourceTypeOpts, 0, len(source))
+	for _, rt := range source {
+		sourceOps = append(sourceOps, fga.UpdateResourceTypeOpts(rt))
+	}
+	diff := diffResourceTypes(target, sourceOps)
+
+	var step syncStep
+	for _, t := range diff.Added {
+		step.changes = append(step.changes, syncChange{Kind: SyncKindResourceTypes, Key: t, Action: SyncActionCreate})
+	}
+	conflicts := 0
+	for _, change := range diff.Changed {
+		// Relations that only exist in the target are kept, so they aren't a conflict
+		if len(change.AddedRelations)+len(change.ChangedRelations) == 0 {
+			continue
+		}
+		conflicts++
+		step.changes = append(step.changes, syncChange{Kind: SyncKindResourceTypes, Key: change.Type, Action: conflictAction(overwrite)})
+	}
+
+	// Resource types are replaced as a whole, so the update includes the target's
+	// own resource types and relations to keep them from being deleted
+	merged := make(map[string]fga.UpdateResourceTypeOpts, len(target)+len(source))
+	for _, rt := range target {
+		merged[rt.Type] = fga.UpdateResourceTypeOpts(rt)
+	}
+	for _, rt := range sourceOps {
+		current, ok := merged[rt.Type]
+		if !ok {
+			merged[rt.Type] = rt
+			continue
+		}
+		if !overwrite {
+			continue
+		}
+		relations := make(map[string]interface{}, len(current.Relations)+len(rt.Relations))
+		for name, relation := range current.Relations {
+			relations[name] = relation
+		}
+		for name, relation := range rt.Relations {
+			relations[name] = relation
+		}
+		merged[rt.Type] = fga.UpdateResourceTypeOpts{Type: rt.Type, Relations: relations}
+	}
+	ops := make([]fga.UpdateResourceTypeOpts, 0, len(merged))
+	for _, rt := range merged {
+		ops = append(ops, rt)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return ops[i].Type < ops[j].Type
+	})
+
+	step.apply = func() error {
+		if len(diff.Added) == 0 && (conflicts == 0 || !overwrite) {
+			return nil
+		}
+		_, err := to.fga.BatchUpdateResourceTypes(context.Background(), ops)
+		if err != nil {
+			return errors.Errorf("error updating resource types: %v", err)
+		}
+		return nil
+	}
+	return step, nil
+}
+
+func planResourceSync(from *envClients, to *envClients, overwrite bool) (syncStep, error) {
+	source, err := listAllResources(from.fga)
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing resources in %s: %v", from.name, err)
+	}
+	target, err := listAllResources(to.fga)
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing resources in %s: %v", to.name, err)
+	}
+	existing := make(map[string]fga.Resource, len(target))
+	for _, resource := range target {
+		existing[resourceKey(resource)] = resource
+	}
+
+	var step syncStep
+	var creates, updates []fga.Resource
+	for _, resource := range source {
+		current, ok := existing[resourceKey(resource)]
+		if !ok {
+			creates = append(creates, resource)
+			step.changes = append(step.changes, syncChange{Kind: SyncKindResources, Key: resourceKey(resource), Action: SyncActionCreate})
+			continue
+		}
+		if jsonEqual(current.Meta, resource.Meta) {
+			continue
+		}
+		action := conflictAction(overwrite)
+		step.changes = append(step.changes, syncChange{Kind: SyncKindResources, Key: resourceKey(resource), Action: action})
+		if action == SyncActionUpdate {
+			updates = append(updates, resource)
+		}
+	}
+
+	step.apply = func() error {
+		for _, resource := range creates {
+			_, err := to.fga.CreateResource(context.Background(), fga.CreateResourceOpts{
+				ResourceType: resource.ResourceType,
+				ResourceId:   resource.ResourceId,
+				Meta:         resource.Meta,
+			})
+			if err != nil {
+				return errors.Errorf("error creating resource %s: %v", resourceKey(resource), err)
+			}
+		}
+		for _, resource := range updates {
+			_, err := to.fga.UpdateResource(context.Background(), fga.UpdateResourceOpts{
+				ResourceType: resource.ResourceType,
+				ResourceId:   resource.ResourceId,
+				Meta:         resource.Meta,
+			})
+			if err != nil {
+				return errors.Errorf("error updating resource %s: %v", resourceKey(resource), err)
+			}
+		}
+		return nil
+	}
+	return step, nil
+}
+
+// planWarrantSync creates the warrants that are missing from the target. Warrants
+// have no attributes besides the ones that identify them, so they never conflict.
+func planWarrantSync(from *envClients, to *envClients) (syncStep, error) {
+	source, err := listAllWarrants(from.fga, fga.ListWarrantsOpts{})
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing warrants in %s: %v", from.name, err)
+	}
+	target, err := listAllWarrants(to.fga, fga.ListWarrantsOpts{})
+	if err != nil {
+		return syncStep{}, errors.Errorf("error listing warrants in %s: %v", to.name, err)
+	}
+	existing := make(map[string]bool, len(target))
+	for _, warrant := range target {
+		existing[warrantAsString(warrant)] = true
+	}
+
+	var step syncStep
+	var writes []fga.WriteWarrantOpts
+	for _, warrant := range source {
+		if existing[warrantAsString(warrant)] {
+			continue
+		}
+		writes = append(writes, warrantWriteOpts(fga.WarrantOpCreate, warrant))
+		step.changes = append(step.changes, syncChange{Kind: SyncKindWarrants, Key: warrantAsString(warrant), Action: SyncActionCreate})
+	}
+
+	step.apply = func() error {
+		for start := 0; start < len(writes); start += warrantBatchSize {
+			end := min(start+warrantBatchSize, len(writes))
+			_, err := to.fga.BatchWriteWarrants(context.Background(), writes[start:end])
+			if err != nil {
+				return errors.Errorf("error writing warrants (%d of %d written): %v", start, len(writes), err)
+			}
+		}
+		return nil
+	}
+	return step, nil
+}
+
+// listAllOrganizations fetches every organization using client
+func listAllOrganizations(client *organizations.Client) ([]organizations.Organization, error) {
+	var orgs []organizations.Organization
+	_, err := list.Walk(
+		list.Options{All: true},
+		func(after string) ([]organizations.Organization, common.ListMetadata, error) {
+			res, err := client.ListOrganizations(context.Background(), organizations.ListOrganizationsOpts{
+				Limit: list.MaxPageSize,
+				After: after,
+			})
+			return res.Data, res.ListMetadata, err
+		},
+		func(org organizations.Organization) error {
+			orgs = append(orgs, org)
+			return nil
+		},
+	)
+	return orgs, err
+}
+
+// organizationDomains returns an organization's domains with their states, sorted so they can be compared
+func organizationDomains(org organizations.Organization) []string {
+	domains := make([]string, 0, len(org.Domains))
+	for _, domain := range organizationDomainData(org) {
+		domains = append(domains, domain.Domain+":"+string(domain.State))
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// organizationDomainData returns an organization's domains as they're sent when creating or
+// updating an organization. Domains keep their verification state, and any state other than
+// verified, such as a failed verification, is copied as pending.
+func organizationDomainData(org organizations.Organization) []organizations.OrganizationDomainData {
+	var domainData []organizations.OrganizationDomainData
+	for _, domain := range org.Domains {
+		state := organizations.Pending
+		if domain.State == organizations.OrganizationDomainVerified || domain.State == organizations.OrganizationDomainLegacyVerified {
+			state = organizations.Verified
+		}
+		domainData = append(domainData, organizations.OrganizationDomainData{Domain: domain.Domain, State: state})
+	}
+	return domainData
+}
@@ -0,0 +1,636 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/workos/workos-cli/internal/list"
+	"github.com/workos/workos-cli/internal/printer"
+	"github.com/workos/workos-go/v4/pkg/common"
+	"github.com/workos/workos-go/v4/pkg/usermanagement"
+)
+
+const (
+	FlagEmail            = "email"
+	FlagEmailVerified    = "email-verified"
+	FlagExpiresInDays    = "expires-in-days"
+	FlagFirstName        = "first-name"
+	FlagInviter          = "inviter"
+	FlagLastName         = "last-name"
+	FlagOrganization     = "organization"
+	FlagPassword         = "password"
+	FlagPasswordResetUrl = "password-reset-url"
+	FlagRole             = "role"
+	FlagStatus           = "status"
+	FlagUser             = "user"
+)
+
+func init() {
+	// users
+	createUserCmd.Flags().String(FlagFirstName, "", "First name of the user")
+	createUserCmd.Flags().String(FlagLastName, "", "Last name of the user")
+	createUserCmd.Flags().String(FlagPassword, "", "Password for the user")
+	createUserCmd.Flags().Bool(FlagEmailVerified, false, "Mark the user's email address as verified")
+	userCmd.AddCommand(createUserCmd)
+	updateUserCmd.Flags().String(FlagFirstName, "", "First name of the user")
+	updateUserCmd.Flags().String(FlagLastName, "", "Last name of the user")
+	updateUserCmd.Flags().String(FlagPassword, "", "New password for the user")
+	updateUserCmd.Flags().Bool(FlagEmailVerified, false, "Mark the user's email address as verified")
+	userCmd.AddCommand(updateUserCmd)
+	userCmd.AddCommand(getUserCmd)
+	listUsersCmd.Flags().String(FlagEmail, "", "Filter by email")
+	listUsersCmd.Flags().String(FlagOrganization, "", "Filter by organization id")
+	list.AddFlags(listUsersCmd.Flags())
+	userCmd.AddCommand(listUsersCmd)
+	userCmd.AddCommand(deleteUserCmd)
+	guard(createUserCmd, updateUserCmd, deleteUserCmd, resetPasswordCmd, sendVerificationCmd)
+	resetPasswordCmd.Flags().String(FlagPasswordResetUrl, "", "URL of your password reset page. The reset token is appended as a query parameter")
+	_ = resetPasswordCmd.MarkFlagRequired(FlagPasswordResetUrl)
+	userCmd.AddCommand(resetPasswordCmd)
+	userCmd.AddCommand(sendVerificationCmd)
+
+	// memberships
+	listMembershipsCmd.Flags().String(FlagOrganization, "", "Filter by organization id")
+	listMembershipsCmd.Flags().String(FlagUser, "", "Filter by user id")
+	listMembershipsCmd.Flags().StringSlice(FlagStatus, nil, "Filter by status (active, inactive or pending)")
+	list.AddFlags(listMembershipsCmd.Flags())
+	membershipCmd.AddCommand(listMembershipsCmd)
+	createMembershipCmd.Flags().String(FlagRole, "", "Slug of the role to assign")
+	membershipCmd.AddCommand(createMembershipCmd)
+	membershipCmd.AddCommand(deactivateMembershipCmd)
+	guard(createMembershipCmd, deactivateMembershipCmd)
+	userCmd.AddCommand(membershipCmd)
+
+	// invitations
+	sendInvitationCmd.Flags().String(FlagOrganization, "", "Organization to invite the user to")
+	sendInvitationCmd.Flags().Int(FlagExpiresInDays, 0, "Number of days until the invitation expires")
+	sendInvitationCmd.Flags().String(FlagInviter, "", "Id of the user sending the invitation")
+	sendInvitationCmd.Flags().String(FlagRole, "", "Slug of the role to assign once the invitation is accepted")
+	invitationCmd.AddCommand(sendInvitationCmd)
+	invitationCmd.AddCommand(revokeInvitationCmd)
+	guard(sendInvitationCmd, revokeInvitationCmd)
+	listInvitationsCmd.Flags().String(FlagEmail, "", "Filter by email")
+	listInvitationsCmd.Flags().String(FlagOrganization, "", "Filter by organization id")
+	list.AddFlags(listInvitationsCmd.Flags())
+	invitationCmd.AddCommand(listInvitationsCmd)
+	userCmd.AddCommand(invitationCmd)
+
+	rootCmd.AddCommand(userCmd)
+}
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Manage users (create, update, delete, etc).",
+	Long:  "Create, update and delete AuthKit users and manage their organization memberships and invitations.",
+}
+
+var createUserCmd = &cobra.Command{
+	Use:     "create <email>",
+	Short:   "Create a new user",
+	Long:    "Create a new user with the specified email address, optionally providing a name and password.",
+	Example: "workos user create john@foo-corp.com --first-name John --last-name Doe --email-verified",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		firstName, err := cmd.Flags().GetString(FlagFirstName)
+		if err != nil {
+			return errors.New("invalid first-name flag")
+		}
+		lastName, err := cmd.Flags().GetString(FlagLastName)
+		if err != nil {
+			return errors.New("invalid last-name flag")
+		}
+		password, err := cmd.Flags().GetString(FlagPassword)
+		if err != nil {
+			return errors.New("invalid password flag")
+		}
+		emailVerified, err := cmd.Flags().GetBool(FlagEmailVerified)
+		if err != nil {
+			return errors.New("invalid email-verified flag")
+		}
+
+		user, err := clients.userManagement.CreateUser(
+			context.Background(),
+			usermanagement.CreateUserOpts{
+				Email:         args[0],
+				Password:      password,
+				FirstName:     firstName,
+				LastName:      lastName,
+				EmailVerified: emailVerified,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error creating user")
+		}
+
+		printer.PrintMsg("Created user")
+		printer.Print(user)
+		return nil
+	},
+}
+
+var updateUserCmd = &cobra.Command{
+	Use:     "update <user_id>",
+	Short:   "Update a user",
+	Long:    "Update a user's name, password or email verification status. Only the provided flags are changed.",
+	Example: "workos user update user_01E4ZCR3C56J083X43JQXF3JK5 --first-name Jane",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		firstName, err := cmd.Flags().GetString(FlagFirstName)
+		if err != nil {
+			return errors.New("invalid first-name flag")
+		}
+		lastName, err := cmd.Flags().GetString(FlagLastName)
+		if err != nil {
+			return errors.New("invalid last-name flag")
+		}
+		password, err := cmd.Flags().GetString(FlagPassword)
+		if err != nil {
+			return errors.New("invalid password flag")
+		}
+		emailVerified, err := cmd.Flags().GetBool(FlagEmailVerified)
+		if err != nil {
+			return errors.New("invalid email-verified flag")
+		}
+
+		user, err := clients.userManagement.UpdateUser(
+			context.Background(),
+			usermanagement.UpdateUserOpts{
+				User:          args[0],
+				FirstName:     firstName,
+				LastName:      lastName,
+				Password:      password,
+				EmailVerified: emailVerified,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error updating user")
+		}
+
+		printer.PrintMsg("Updated user")
+		printer.Print(user)
+		return nil
+	},
+}
+
+var getUserCmd = &cobra.Command{
+	Use:     "get <user_id>",
+	Short:   "Get a user",
+	Long:    "Get a user by id. Find the user's id by listing your users.",
+	Example: "workos user get user_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		user, err := clients.userManagement.GetUser(
+			context.Background(),
+			usermanagement.GetUserOpts{
+				User: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error getting user")
+		}
+
+		printer.Print(user)
+		return nil
+	},
+}
+
+var listUsersCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users with optional filters",
+	Long:  "List users, optionally filtering by email or organization, limit, before/after cursor, and order (asc/desc). Use --all to fetch every page.",
+	Example: `workos user list --organization org_01EHZNVPK3SFK441A1RGBFSHRT --limit 10 --order desc
+workos user list --email john@foo-corp.com`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		email, err := cmd.Flags().GetString(FlagEmail)
+		if err != nil {
+			return errors.New("invalid email flag")
+		}
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+
+		p := printer.NewListPrinter(120, "ID", "Email", "Name", "Email Verified")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]usermanagement.User, common.ListMetadata, error) {
+				users, err := clients.userManagement.ListUsers(
+					context.Background(),
+					usermanagement.ListUsersOpts{
+						Email:          email,
+						OrganizationID: organization,
+						Limit:          limit,
+						Before:         before,
+						After:          after,
+						Order:          usermanagement.Order(order),
+					},
+				)
+				return users.Data, users.ListMetadata, err
+			},
+			func(user usermanagement.User) error {
+				p.Add(
+					user,
+					user.ID,
+					user.Email,
+					fmt.Sprintf("%s %s", user.FirstName, user.LastName),
+					strconv.FormatBool(user.EmailVerified),
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing users")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var deleteUserCmd = &cobra.Command{
+	Use:     "delete <user_id>",
+	Short:   "Delete a user",
+	Long:    "Delete a user by id. Find the user's id by listing your users.",
+	Example: "workos user delete user_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		userId := args[0]
+		err := clients.userManagement.DeleteUser(
+			context.Background(),
+			usermanagement.DeleteUserOpts{
+				User: userId,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error deleting user")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Deleted user %s", userId), deletedResult{ID: userId, Deleted: true})
+		return nil
+	},
+}
+
+var resetPasswordCmd = &cobra.Command{
+	Use:     "reset-password <email>",
+	Short:   "Send a password reset email",
+	Long:    "Send a password reset email to a user. The email links to the provided password reset URL with a reset token.",
+	Example: "workos user reset-password john@foo-corp.com --password-reset-url https://foo-corp.com/reset-password",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		passwordResetUrl, err := cmd.Flags().GetString(FlagPasswordResetUrl)
+		if err != nil {
+			return errors.New("invalid password-reset-url flag")
+		}
+
+		err = clients.userManagement.SendPasswordResetEmail(
+			context.Background(),
+			usermanagement.SendPasswordResetEmailOpts{
+				Email:            args[0],
+				PasswordResetUrl: passwordResetUrl,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error sending password reset email")
+		}
+
+		printer.PrintResult(
+			fmt.Sprintf("Sent password reset email to %s", args[0]),
+			map[string]string{"email": args[0]},
+		)
+		return nil
+	},
+}
+
+var sendVerificationCmd = &cobra.Command{
+	Use:     "send-verification <user_id>",
+	Short:   "Send an email verification code",
+	Long:    "Send an email containing a verification code to a user's email address.",
+	Example: "workos user send-verification user_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		res, err := clients.userManagement.SendVerificationEmail(
+			context.Background(),
+			usermanagement.SendVerificationEmailOpts{
+				User: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error sending verification email")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Sent verification email to %s", res.User.Email), res.User)
+		return nil
+	},
+}
+
+var membershipCmd = &cobra.Command{
+	Use:   "membership",
+	Short: "Manage organization memberships",
+	Long:  "List, create and deactivate the memberships that associate users with organizations.",
+}
+
+var listMembershipsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List organization memberships",
+	Long:    "List organization memberships, filtering by organization or user and optionally by status.",
+	Example: "workos user membership list --organization org_01EHZNVPK3SFK441A1RGBFSHRT --status active",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+		user, err := cmd.Flags().GetString(FlagUser)
+		if err != nil {
+			return errors.New("invalid user flag")
+		}
+		if organization == "" && user == "" {
+			return errors.New("either --organization or --user is required")
+		}
+		statusFlags, err := cmd.Flags().GetStringSlice(FlagStatus)
+		if err != nil {
+			return errors.New("invalid status flag")
+		}
+		var statuses []usermanagement.OrganizationMembershipStatus
+		for _, status := range statusFlags {
+			statuses = append(statuses, usermanagement.OrganizationMembershipStatus(status))
+		}
+
+		p := printer.NewListPrinter(120, "ID", "User ID", "Organization ID", "Role", "Status")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]usermanagement.OrganizationMembership, common.ListMetadata, error) {
+				memberships, err := clients.userManagement.ListOrganizationMemberships(
+					context.Background(),
+					usermanagement.ListOrganizationMembershipsOpts{
+						OrganizationID: organization,
+						UserID:         user,
+						Statuses:       statuses,
+						Limit:          limit,
+						Before:         before,
+						After:          after,
+						Order:          usermanagement.Order(order),
+					},
+				)
+				return memberships.Data, memberships.ListMetadata, err
+			},
+			func(membership usermanagement.OrganizationMembership) error {
+				p.Add(
+					membership,
+					membership.ID,
+					membership.UserID,
+					membership.OrganizationID,
+					membership.Role.Slug,
+					string(membership.Status),
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing organization memberships")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
+
+var createMembershipCmd = &cobra.Command{
+	Use:     "create <user_id> <organization_id>",
+	Short:   "Add a user to an organization",
+	Long:    "Create an organization membership for a user, optionally assigning a role.",
+	Example: "workos user membership create user_01E4ZCR3C56J083X43JQXF3JK5 org_01EHZNVPK3SFK441A1RGBFSHRT --role admin",
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		role, err := cmd.Flags().GetString(FlagRole)
+		if err != nil {
+			return errors.New("invalid role flag")
+		}
+
+		membership, err := clients.userManagement.CreateOrganizationMembership(
+			context.Background(),
+			usermanagement.CreateOrganizationMembershipOpts{
+				UserID:         args[0],
+				OrganizationID: args[1],
+				RoleSlug:       role,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error creating organization membership")
+		}
+
+		printer.PrintMsg("Created organization membership")
+		printer.Print(membership)
+		return nil
+	},
+}
+
+var deactivateMembershipCmd = &cobra.Command{
+	Use:     "deactivate <organization_membership_id>",
+	Short:   "Deactivate an organization membership",
+	Long:    "Deactivate an organization membership, removing the user's access to the organization while retaining the membership's history.",
+	Example: "workos user membership deactivate om_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		membership, err := clients.userManagement.DeactivateOrganizationMembership(
+			context.Background(),
+			usermanagement.DeactivateOrganizationMembershipOpts{
+				OrganizationMembership: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error deactivating organization membership")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Deactivated organization membership %s", membership.ID), membership)
+		return nil
+	},
+}
+
+var invitationCmd = &cobra.Command{
+	Use:   "invitation",
+	Short: "Manage invitations",
+	Long:  "Send, revoke and list invitations for users to sign up to your application or join an organization.",
+}
+
+var sendInvitationCmd = &cobra.Command{
+	Use:     "send <email>",
+	Short:   "Send an invitation",
+	Long:    "Send an invitation email to the specified address, optionally inviting them to an organization with a role.",
+	Example: "workos user invitation send john@foo-corp.com --organization org_01EHZNVPK3SFK441A1RGBFSHRT --expires-in-days 7",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+		expiresInDays, err := cmd.Flags().GetInt(FlagExpiresInDays)
+		if err != nil {
+			return errors.New("invalid expires-in-days flag")
+		}
+		inviter, err := cmd.Flags().GetString(FlagInviter)
+		if err != nil {
+			return errors.New("invalid inviter flag")
+		}
+		role, err := cmd.Flags().GetString(FlagRole)
+		if err != nil {
+			return errors.New("invalid role flag")
+		}
+
+		invitation, err := clients.userManagement.SendInvitation(
+			context.Background(),
+			usermanagement.SendInvitationOpts{
+				Email:          args[0],
+				OrganizationID: organization,
+				ExpiresInDays:  expiresInDays,
+				InviterUserID:  inviter,
+				RoleSlug:       role,
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error sending invitation")
+		}
+
+		printer.PrintMsg("Sent invitation")
+		printer.Print(invitation)
+		return nil
+	},
+}
+
+var revokeInvitationCmd = &cobra.Command{
+	Use:     "revoke <invitation_id>",
+	Short:   "Revoke an invitation",
+	Long:    "Revoke a pending invitation so that it can no longer be accepted.",
+	Example: "workos user invitation revoke invitation_01E4ZCR3C56J083X43JQXF3JK5",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		invitation, err := clients.userManagement.RevokeInvitation(
+			context.Background(),
+			usermanagement.RevokeInvitationOpts{
+				Invitation: args[0],
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error revoking invitation")
+		}
+
+		printer.PrintResult(fmt.Sprintf("Revoked invitation %s", invitation.ID), invitation)
+		return nil
+	},
+}
+
+var listInvitationsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List invitations",
+	Long:    "List invitations, optionally filtering by email or organization.",
+	Example: "workos user invitation list --organization org_01EHZNVPK3SFK441A1RGBFSHRT",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clients := newClients()
+		listOpts, limit, err := list.GetOptions(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		before, err := cmd.Flags().GetString(list.FlagBefore)
+		if err != nil {
+			return errors.New("invalid before flag")
+		}
+		order, err := cmd.Flags().GetString(list.FlagOrder)
+		if err != nil {
+			return errors.New("invalid order flag")
+		}
+		email, err := cmd.Flags().GetString(FlagEmail)
+		if err != nil {
+			return errors.New("invalid email flag")
+		}
+		organization, err := cmd.Flags().GetString(FlagOrganization)
+		if err != nil {
+			return errors.New("invalid organization flag")
+		}
+
+		p := printer.NewListPrinter(120, "ID", "Email", "Organization ID", "State", "Expires At")
+		metadata, err := list.Walk(
+			listOpts,
+			func(after string) ([]usermanagement.Invitation, common.ListMetadata, error) {
+				invitations, err := clients.userManagement.ListInvitations(
+					context.Background(),
+					usermanagement.ListInvitationsOpts{
+						Email:          email,
+						OrganizationID: organization,
+						Limit:          limit,
+						Before:         before,
+						After:          after,
+						Order:          usermanagement.Order(order),
+					},
+				)
+				return invitations.Data, invitations.ListMetadata, err
+			},
+			func(invitation usermanagement.Invitation) error {
+				p.Add(
+					invitation,
+					invitation.ID,
+					invitation.Email,
+					invitation.OrganizationID,
+					string(invitation.State),
+					invitation.ExpiresAt,
+				)
+				return nil
+			},
+		)
+		if err != nil {
+			return errors.Wrap(err, "error listing invitations")
+		}
+
+		if !listOpts.All {
+			p.SetCursors(metadata.Before, metadata.After)
+		}
+		p.Flush()
+		return nil
+	},
+}
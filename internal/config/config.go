@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/viper"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -21,37 +22,115 @@ const (
 type Config struct {
 	ActiveEnvironment string                 `mapstructure:"active_environment" json:"active_environment"`
 	Environments      map[string]Environment `mapstructure:"environments"       json:"environments"`
+
+	// Set by LoadConfig so that values from a project file or environment variables
+	// aren't copied into the user file by Write
+	user              *Config
+	loadedActive      string
+	loadedEnvironment map[string]Environment
+	origins           map[string]string
+	warnings          []string
+	// Hash of the user file when it was loaded, used by Write to detect changes made by other processes
+	loadedHash string
 }
 
+// Environment is a configured WorkOS environment. ApiKeyRef points to the API
+// key in a secret store (e.g. keyring:production) and replaces ApiKey when set.
 type Environment struct {
-	Endpoint string `mapstructure:"endpoint" json:"endpoint"`
-	Name     string `mapstructure:"name"     json:"name"`
-	Type     string `mapstructure:"type"     json:"type"`
-	ApiKey   string `mapstructure:"api_key"    json:"api_key"`
+	Endpoint  string `mapstructure:"endpoint"    json:"endpoint"`
+	Name      string `mapstructure:"name"        json:"name"`
+	Type      string `mapstructure:"type"        json:"type"`
+	ApiKey    string `mapstructure:"api_key"     json:"api_key,omitempty"`
+	ApiKeyRef string `mapstructure:"api_key_ref" json:"api_key_ref,omitempty"`
 }
 
-func (c Config) Write() error {
-	fileContents, err := json.MarshalIndent(c, "", "    ")
+// Write saves the config to the user file. Values that came from a project file or
+// an environment variable and haven't changed are replaced with the user file's own
+// values, so they're never copied into it. The file is replaced atomically while
+// holding a lock, and the previous version is kept as a backup (see Backups). Write
+// fails with ErrConfigChanged if another process changed the file since it was loaded.
+func (c *Config) Write() error {
+	persisted := Config{ActiveEnvironment: c.ActiveEnvironment}
+	environments := make(map[string]Environment, len(c.Environments))
+	for name, env := range c.Environments {
+		environments[name] = persistedEnvironment(env)
+	}
+	if c.user != nil {
+		if c.OverriddenBy("active_environment") != "" && c.ActiveEnvironment == c.loadedActive {
+			persisted.ActiveEnvironment = c.user.ActiveEnvironment
+		}
+		for name, env := range environments {
+			loaded, ok := c.loadedEnvironment[name]
+			if !ok {
+				continue
+			}
+			userEnv, inUserFile := c.user.Environments[name]
+			if !inUserFile && env == loaded {
+				delete(environments, name)
+				continue
+			}
+			environments[name] = c.restoreOverridden(name, env, loaded, persistedEnvironment(userEnv))
+		}
+	}
+	persisted.Environments = environments
+
+	fileContents, err := json.MarshalIndent(persisted, "", "    ")
 	if err != nil {
 		return err
 	}
-	homeDir, err := os.UserHomeDir()
+	path, err := FilePath()
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(homeDir+"/"+FileName, fileContents, 0644)
+	hash, err := writeFile(path, fileContents, c.loadedHash)
 	if err != nil {
 		return err
 	}
+	c.loadedHash = hash
 	return nil
 }
 
+// restoreOverridden replaces each field of env that came from a project file or an
+// environment variable, and still has the value it was loaded with, by the user file's value
+func (c *Config) restoreOverridden(name string, env Environment, loaded Environment, userEnv Environment) Environment {
+	prefix := "environments." + name + "."
+	fields := []struct {
+		key    string
+		value  *string
+		loaded string
+		user   string
+	}{
+		{"endpoint", &env.Endpoint, loaded.Endpoint, userEnv.Endpoint},
+		{"name", &env.Name, loaded.Name, userEnv.Name},
+		{"type", &env.Type, loaded.Type, userEnv.Type},
+		{"api_key", &env.ApiKey, loaded.ApiKey, userEnv.ApiKey},
+		{"api_key_ref", &env.ApiKeyRef, loaded.ApiKeyRef, userEnv.ApiKeyRef},
+	}
+	for _, field := range fields {
+		if c.OverriddenBy(prefix+field.key) != "" && *field.value == field.loaded {
+			*field.value = field.user
+		}
+	}
+	return env
+}
+
+// persistedEnvironment returns env as it's written to a config file. API keys
+// that were resolved from a secret store are never persisted.
+func persistedEnvironment(env Environment) Environment {
+	if env.ApiKeyRef != "" {
+		env.ApiKey = ""
+	}
+	return env
+}
+
 // Creates an empty config file if it doesn't exist
-func createEmptyConfigFile(dir string) {
-	_, err := os.Stat(dir + "/" + FileName)
+func createEmptyConfigFile(path string) {
+	_, err := os.Stat(path)
 	if errors.Is(err, fs.ErrNotExist) {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		cobra.CheckErr(err)
 		emptyJson := []byte("{}")
-		err = os.WriteFile(dir+"/"+FileName, emptyJson, 0644)
+		err = os.WriteFile(path, emptyJson, 0600)
 		cobra.CheckErr(err)
 	}
 }
@@ -78,24 +157,54 @@ func loadEnvVarOverrides() {
 	}
 }
 
+// LoadConfig loads the user file and merges the project file, if there is one,
+// and environment variables over it. See the precedence in discovery.go.
 func LoadConfig() *Config {
-	homeDir, err := os.UserHomeDir()
+	userPath, err := FilePath()
+	cobra.CheckErr(err)
+	createEmptyConfigFile(userPath)
+	projectPath, err := ProjectFilePath()
 	cobra.CheckErr(err)
-	createEmptyConfigFile(homeDir)
-
-	// Load config from ~/.workos.json
-	viper.AddConfigPath(homeDir)
-	viper.SetConfigType(FileExtension)
-	viper.SetConfigName(FilePrefix)
 
+	viper.SetConfigFile(userPath)
 	loadEnvVarOverrides()
-
 	err = viper.ReadInConfig()
 	cobra.CheckErr(err)
+	if projectPath != "" {
+		viper.SetConfigFile(projectPath)
+		err = viper.MergeInConfig()
+		cobra.CheckErr(err)
+	}
 
 	// Unmarshal config & set warrant client vals
 	var config Config
 	err = viper.Unmarshal(&config)
 	cobra.CheckErr(err)
+
+	user, err := readConfigFile(userPath)
+	cobra.CheckErr(err)
+	userContents, err := os.ReadFile(userPath)
+	cobra.CheckErr(err)
+	config.loadedHash = hashContents(userContents)
+	config.origins = origins(user, userPath)
+	if projectPath != "" {
+		project, err := readConfigFile(projectPath)
+		cobra.CheckErr(err)
+		config.warnings = restrictProjectFile(&config, user, project, projectPath)
+		for key, origin := range origins(project, projectPath) {
+			config.origins[key] = origin
+		}
+	}
+	for key := range config.values() {
+		if envVar := envVarName(key); os.Getenv(envVar) != "" {
+			config.origins[key] = "env " + envVar
+		}
+	}
+	config.user = user
+	config.loadedActive = config.ActiveEnvironment
+	config.loadedEnvironment = make(map[string]Environment, len(config.Environments))
+	for name, env := range config.Environments {
+		config.loadedEnvironment[name] = persistedEnvironment(env)
+	}
 	return &config
 }
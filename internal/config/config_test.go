@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupConfigFile points the user file at a temporary file with contents and moves to a
+// directory without a project file
+func setupConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	home := t.TempDir()
+	path := filepath.Join(home, FileName)
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv(EnvVarConfig, path)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(home); err != nil {
+		t.Fatal(err)
+	}
+	viper.Reset()
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+		viper.Reset()
+	})
+	return path
+}
+
+func TestWriteSkipsEnvVarOverrides(t *testing.T) {
+	path := setupConfigFile(t, `{
+		"active_environment": "staging",
+		"environments": {
+			"staging": {"name": "staging", "type": "Sandbox", "api_key": "sk_test_file"},
+			"local": {"name": "local", "type": "Sandbox", "api_key_ref": "keyring:local"}
+		}
+	}`)
+	t.Setenv("WORKOS_ENVIRONMENTS_STAGING_API_KEY", "sk_test_env")
+
+	cfg := LoadConfig()
+	if got := cfg.Environments["staging"].ApiKey; got != "sk_test_env" {
+		t.Fatalf("expected the environment variable to override the API key, got %q", got)
+	}
+	cfg.ActiveEnvironment = "local"
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	written := readWritten(t, path)
+	if strings.Contains(written, "sk_test_env") {
+		t.Errorf("API key from the environment variable was written to the user file:\n%s", written)
+	}
+	if !strings.Contains(written, "sk_test_file") {
+		t.Errorf("expected the user file's API key to be kept:\n%s", written)
+	}
+	if !strings.Contains(written, `"active_environment": "local"`) {
+		t.Errorf("expected the changed active environment to be written:\n%s", written)
+	}
+}
+
+func TestWriteSkipsHeadlessEnvironment(t *testing.T) {
+	path := setupConfigFile(t, `{
+		"active_environment": "staging",
+		"environments": {
+			"staging": {"name": "staging", "type": "Sandbox", "api_key_ref": "keyring:staging"}
+		}
+	}`)
+	t.Setenv("WORKOS_ACTIVE_ENVIRONMENT", EnvVarHeadlessMode)
+	t.Setenv("WORKOS_ENVIRONMENTS_HEADLESS_API_KEY", "sk_test_headless")
+
+	cfg := LoadConfig()
+	if cfg.ActiveEnvironment != EnvVarHeadlessMode {
+		t.Fatalf("expected the headless environment to be active, got %q", cfg.ActiveEnvironment)
+	}
+	cfg.Environments["local"] = Environment{Name: "local", Type: "Sandbox", ApiKeyRef: "keyring:local"}
+	if err := cfg.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	written := readWritten(t, path)
+	if strings.Contains(written, "sk_test_headless") || strings.Contains(written, EnvVarHeadlessMode) {
+		t.Errorf("headless environment from environment variables was written to the user file:\n%s", written)
+	}
+	if !strings.Contains(written, `"active_environment": "staging"`) {
+		t.Errorf("expected the user file's active environment to be kept:\n%s", written)
+	}
+	if !strings.Contains(written, `"keyring:local"`) {
+		t.Errorf("expected the added environment to be written:\n%s", written)
+	}
+}
+
+func readWritten(t *testing.T, path string) string {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(contents)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const CursorsFileName = FilePrefix + ".cursors"
+
+// LoadEventsCursor returns the last events cursor saved under key, which identifies
+// the environment and filters of a tail
+func LoadEventsCursor(key string) (string, error) {
+	cursors, err := readCursors()
+	if err != nil {
+		return "", err
+	}
+	return cursors[key], nil
+}
+
+// SaveEventsCursor persists the last events cursor under key. An empty cursor
+// removes the entry.
+func SaveEventsCursor(key string, cursor string) error {
+	cursors, err := readCursors()
+	if err != nil {
+		return err
+	}
+	if cursor == "" {
+		delete(cursors, key)
+	} else {
+		cursors[key] = cursor
+	}
+
+	fileContents, err := json.MarshalIndent(cursors, "", "    ")
+	if err != nil {
+		return err
+	}
+	path, err := cursorsPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, fileContents, 0600)
+}
+
+func readCursors() (map[string]string, error) {
+	path, err := cursorsPath()
+	if err != nil {
+		return nil, err
+	}
+	cursors := map[string]string{}
+	fileContents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cursors, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(fileContents, &cursors)
+	return cursors, err
+}
+
+func cursorsPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, CursorsFileName), nil
+}
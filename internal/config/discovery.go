@@ -0,0 +1,203 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Config values are merged from these sources, from highest to lowest precedence:
+//
+//  1. Command line flags (--env, --api-key and --endpoint)
+//  2. Environment variables (e.g. WORKOS_ACTIVE_ENVIRONMENT)
+//  3. The project file: .workos.json or .workos.yaml in the working directory or the closest parent directory
+//  4. The user file: $WORKOS_CONFIG, $XDG_CONFIG_HOME/workos/config.json if it exists, or ~/.workos.json
+const (
+	EnvVarConfig      = EnvVarPrefix + "_CONFIG"
+	EnvVarXdgConfig   = "XDG_CONFIG_HOME"
+	XdgConfigDir      = "workos"
+	XdgConfigFileName = "config.json"
+)
+
+// A project file can come from any repository that's cloned, so it can't point an environment
+// whose API key comes from the user file or a secret store at a different endpoint, or change its
+// type and with it the confirmation Production environments require. Those values are only
+// used when the project file also sets the environment's api_key.
+
+// ProjectFileNames are the names of project files, in the order they're looked for in a directory
+var ProjectFileNames = []string{FilePrefix + ".json", FilePrefix + ".yaml"}
+
+// Setting is an effective config value along with where it was set
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// FilePath returns the path of the user config file, which is where config changes are written
+func FilePath() (string, error) {
+	if path := os.Getenv(EnvVarConfig); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	xdgConfigHome := os.Getenv(EnvVarXdgConfig)
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(homeDir, ".config")
+	}
+	xdgPath := filepath.Join(xdgConfigHome, XdgConfigDir, XdgConfigFileName)
+	if _, err := os.Stat(xdgPath); err == nil {
+		return xdgPath, nil
+	}
+	return filepath.Join(homeDir, FileName), nil
+}
+
+// ProjectFilePath looks for a project file in the working directory and its parents,
+// stopping at the home directory since ~/.workos.json is the user file. It returns an
+// empty path if there is no project file.
+func ProjectFilePath() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	homeDir, _ := os.UserHomeDir()
+	for dir != homeDir {
+		for _, name := range ProjectFileNames {
+			path := filepath.Join(dir, name)
+			_, err := os.Stat(path)
+			if err == nil {
+				return path, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", nil
+}
+
+// Warnings returns problems found while loading the config, e.g. project file values that were ignored
+func (c Config) Warnings() []string {
+	return c.warnings
+}
+
+// restrictProjectFile undoes the values in project that cfg must not take from a project file
+// (see above), and removes them from project so they're not reported as its settings
+func restrictProjectFile(cfg *Config, user *Config, project *Config, path string) []string {
+	var warnings []string
+	for name, projectEnv := range project.Environments {
+		if projectEnv.ApiKey != "" {
+			continue
+		}
+		env := cfg.Environments[name]
+		userEnv, inUserFile := user.Environments[name]
+		prefix := "environments." + name + "."
+
+		if projectEnv.Endpoint != "" && os.Getenv(envVarName(prefix+"endpoint")) == "" {
+			env.Endpoint = userEnv.Endpoint
+			projectEnv.Endpoint = ""
+			warnings = append(warnings, fmt.Sprintf("ignoring the endpoint for environment %s in %s since its API key isn't set there", name, path))
+		}
+		if projectEnv.Type != "" && inUserFile && projectEnv.Type != userEnv.Type && os.Getenv(envVarName(prefix+"type")) == "" {
+			env.Type = userEnv.Type
+			projectEnv.Type = ""
+			warnings = append(warnings, fmt.Sprintf("ignoring the type for environment %s in %s since its API key isn't set there", name, path))
+		}
+		if _, ok := cfg.Environments[name]; ok {
+			cfg.Environments[name] = env
+		}
+		project.Environments[name] = projectEnv
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// Settings returns every effective config value sorted by key. API keys that were resolved
+// from a secret store are left out in favor of the reference to them.
+func (c Config) Settings() []Setting {
+	values := c.values()
+	settings := make([]Setting, 0, len(values))
+	for key, value := range values {
+		settings = append(settings, Setting{Key: key, Value: value, Origin: c.origins[key]})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// OverriddenBy returns the project file or environment variable that sets key over the
+// user file, or an empty string if the value comes from the user file
+func (c Config) OverriddenBy(key string) string {
+	origin := c.origins[key]
+	if path, err := FilePath(); err == nil && origin == path {
+		return ""
+	}
+	return origin
+}
+
+// values flattens the config into keys like environments.local.endpoint, skipping empty values
+func (c Config) values() map[string]string {
+	values := map[string]string{}
+	if c.ActiveEnvironment != "" {
+		values["active_environment"] = c.ActiveEnvironment
+	}
+	for name, env := range c.Environments {
+		env = persistedEnvironment(env)
+		prefix := "environments." + name + "."
+		fields := map[string]string{
+			"endpoint":    env.Endpoint,
+			"name":        env.Name,
+			"type":        env.Type,
+			"api_key":     env.ApiKey,
+			"api_key_ref": env.ApiKeyRef,
+		}
+		for key, value := range fields {
+			if value != "" {
+				values[prefix+key] = value
+			}
+		}
+	}
+	return values
+}
+
+// origins records path as the origin of every value set in cfg
+func origins(cfg *Config, path string) map[string]string {
+	origins := map[string]string{}
+	for key := range cfg.values() {
+		origins[key] = path
+	}
+	return origins
+}
+
+// readConfigFile reads a single config file without environment variable overrides
+func readConfigFile(path string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	err = v.Unmarshal(&cfg)
+	return &cfg, err
+}
+
+// envVarName returns the environment variable that overrides a config key
+func envVarName(key string) string {
+	return EnvVarPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
@@ -0,0 +1,310 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	SecretStoreKeyring   = "keyring"
+	SecretStoreFile      = "file"
+	SecretStorePlaintext = "plaintext"
+
+	EnvVarSecretsPassphrase = EnvVarPrefix + "_SECRETS_PASSPHRASE"
+	KeyringService          = "workos-cli"
+	SecretsFileName         = FilePrefix + ".secrets"
+)
+
+var SecretStores = []string{SecretStoreKeyring, SecretStoreFile, SecretStorePlaintext}
+
+// fileStores are shared by every NewSecretStore call in an invocation, so the
+// passphrase is only read and the key only derived once
+var (
+	fileStoresMu sync.Mutex
+	fileStores   = map[string]*fileStore{}
+)
+
+// PassphrasePrompt is called to read the passphrase for the encrypted file
+// store when WORKOS_SECRETS_PASSPHRASE is not set
+var PassphrasePrompt func() (string, error)
+
+// SecretStore persists API keys outside of the config file
+type SecretStore interface {
+	Get(name string) (string, error)
+	Set(name string, secret string) error
+	Delete(name string) error
+}
+
+// NewSecretStore returns the secret store backend with the given name
+func NewSecretStore(backend string) (SecretStore, error) {
+	switch backend {
+	case SecretStoreKeyring:
+		return keyringStore{}, nil
+	case SecretStoreFile:
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(homeDir, SecretsFileName)
+		fileStoresMu.Lock()
+		defer fileStoresMu.Unlock()
+		store, ok := fileStores[path]
+		if !ok {
+			store = &fileStore{path: path}
+			fileStores[path] = store
+		}
+		return store, nil
+	default:
+		return nil, errors.Errorf("invalid secret store: %s (must be one of %s)", backend, strings.Join(SecretStores, ", "))
+	}
+}
+
+// KeyringAvailable reports whether the OS keyring can be reached. It can't on
+// e.g. headless Linux machines without a Secret Service daemon.
+func KeyringAvailable() bool {
+	_, err := keyring.Get(KeyringService, KeyringService)
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
+}
+
+// ParseSecretRef splits a reference of the form <backend>:<name>
+func ParseSecretRef(ref string) (backend string, name string, err error) {
+	backend, name, ok := strings.Cut(ref, ":")
+	if !ok || name == "" {
+		return "", "", errors.Errorf("invalid api key reference: %s", ref)
+	}
+	return backend, name, nil
+}
+
+// ResolveApiKey returns the environment's API key, reading it from its secret
+// store if the config only holds a reference to it
+func (e Environment) ResolveApiKey() (string, error) {
+	if e.ApiKey != "" || e.ApiKeyRef == "" {
+		return e.ApiKey, nil
+	}
+	backend, name, err := ParseSecretRef(e.ApiKeyRef)
+	if err != nil {
+		return "", err
+	}
+	store, err := NewSecretStore(backend)
+	if err != nil {
+		return "", err
+	}
+	apiKey, err := store.Get(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "error reading api key for environment %s from %s store", e.Name, backend)
+	}
+	return apiKey, nil
+}
+
+// StoreApiKey moves the environment's API key into the given secret store and
+// replaces it with a reference. The plaintext backend keeps the key inline.
+func (e *Environment) StoreApiKey(backend string) error {
+	if backend == SecretStorePlaintext {
+		e.ApiKeyRef = ""
+		return nil
+	}
+	store, err := NewSecretStore(backend)
+	if err != nil {
+		return err
+	}
+	err = store.Set(e.Name, e.ApiKey)
+	if err != nil {
+		if backend == SecretStoreKeyring {
+			return errors.Wrapf(err, "error writing api key to keyring (use --secret-store %s if no keyring is available)", SecretStoreFile)
+		}
+		return errors.Wrapf(err, "error writing api key to %s store", backend)
+	}
+	e.ApiKeyRef = backend + ":" + e.Name
+	e.ApiKey = ""
+	return nil
+}
+
+// DeleteApiKey removes the environment's API key from its secret store, if any
+func (e Environment) DeleteApiKey() error {
+	if e.ApiKeyRef == "" {
+		return nil
+	}
+	backend, name, err := ParseSecretRef(e.ApiKeyRef)
+	if err != nil {
+		return err
+	}
+	store, err := NewSecretStore(backend)
+	if err != nil {
+		return err
+	}
+	return store.Delete(name)
+}
+
+// keyringStore stores secrets in the OS keyring (Secret Service on Linux,
+// Keychain on macOS and Credential Manager on Windows)
+type keyringStore struct{}
+
+func (keyringStore) Get(name string) (string, error) {
+	return keyring.Get(KeyringService, name)
+}
+
+func (keyringStore) Set(name string, secret string) error {
+	return keyring.Set(KeyringService, name, secret)
+}
+
+func (keyringStore) Delete(name string) error {
+	err := keyring.Delete(KeyringService, name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// fileStore stores secrets in a file encrypted with AES-GCM using a key
+// derived from a passphrase with scrypt. It works without a keyring daemon,
+// e.g. on headless machines.
+type fileStore struct {
+	path       string
+	passphrase string
+	// key is derived from the passphrase with salt
+	key  []byte
+	salt []byte
+}
+
+type secretsFile struct {
+	Salt    []byte            `json:"salt"`
+	Secrets map[string][]byte `json:"secrets"`
+}
+
+func (s *fileStore) Get(name string) (string, error) {
+	file, err := s.read()
+	if err != nil {
+		return "", err
+	}
+	sealed, ok := file.Secrets[name]
+	if !ok {
+		return "", errors.Errorf("no secret named %s in %s", name, s.path)
+	}
+	gcm, err := s.cipher(file.Salt)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.Errorf("secret %s in %s is corrupt", name, s.path)
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return "", errors.New("unable to decrypt secret (wrong passphrase?)")
+	}
+	return string(plaintext), nil
+}
+
+func (s *fileStore) Set(name string, secret string) error {
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	gcm, err := s.cipher(file.Salt)
+	if err != nil {
+		return err
+	}
+	// Verify the passphrase against an existing secret so that a typo doesn't
+	// leave the file encrypted under two different keys
+	for existingName, sealed := range file.Secrets {
+		if len(sealed) < gcm.NonceSize() {
+			continue
+		}
+		_, err = gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(existingName))
+		if err != nil {
+			return errors.New("passphrase does not match existing secrets")
+		}
+		break
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+	file.Secrets[name] = gcm.Seal(nonce, nonce, []byte(secret), []byte(name))
+	return s.write(file)
+}
+
+func (s *fileStore) Delete(name string) error {
+	file, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Secrets[name]; !ok {
+		return nil
+	}
+	delete(file.Secrets, name)
+	return s.write(file)
+}
+
+func (s *fileStore) read() (*secretsFile, error) {
+	file := &secretsFile{Secrets: make(map[string][]byte)}
+	bytes, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		file.Salt = make([]byte, 16)
+		_, err = io.ReadFull(rand.Reader, file.Salt)
+		return file, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bytes, file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading %s", s.path)
+	}
+	if file.Secrets == nil {
+		file.Secrets = make(map[string][]byte)
+	}
+	return file, nil
+}
+
+func (s *fileStore) write(file *secretsFile) error {
+	bytes, err := json.MarshalIndent(file, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, bytes, 0600)
+}
+
+func (s *fileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.key == nil || !bytes.Equal(s.salt, salt) {
+		if s.passphrase == "" {
+			passphrase := os.Getenv(EnvVarSecretsPassphrase)
+			if passphrase == "" {
+				if PassphrasePrompt == nil {
+					return nil, errors.Errorf("%s must be set to use the %s secret store", EnvVarSecretsPassphrase, SecretStoreFile)
+				}
+				var err error
+				passphrase, err = PassphrasePrompt()
+				if err != nil {
+					return nil, err
+				}
+			}
+			s.passphrase = passphrase
+		}
+		key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32)
+		if err != nil {
+			return nil, err
+		}
+		s.key = key
+		s.salt = salt
+	}
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestFileStorePromptsOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvVarSecretsPassphrase, "")
+	prompts := 0
+	PassphrasePrompt = func() (string, error) {
+		prompts++
+		return "passphrase", nil
+	}
+	t.Cleanup(func() { PassphrasePrompt = nil })
+
+	environments := []Environment{
+		{Name: "staging", ApiKey: "sk_test_staging"},
+		{Name: "production", ApiKey: "sk_live_production"},
+		{Name: "local", ApiKey: "sk_test_local"},
+	}
+	for i := range environments {
+		if err := environments[i].StoreApiKey(SecretStoreFile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, env := range environments {
+		apiKey, err := env.ResolveApiKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if apiKey == "" || env.ApiKey != "" {
+			t.Errorf("expected the key for %s to be resolved from the file store", env.Name)
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("expected the passphrase to be read once, got %d prompts", prompts)
+	}
+}
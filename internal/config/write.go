@@ -0,0 +1,244 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// BackupDirName is the directory next to the config file that backups are kept in
+	BackupDirName = FilePrefix + "-backups"
+	// MaxBackups is how many backups are kept before the oldest are removed
+	MaxBackups = 10
+
+	backupTimeFormat = "20060102-150405.000000"
+	lockTimeout      = 5 * time.Second
+	// staleLockAge is how old a lock file has to be before it's assumed to be left
+	// behind by a process that crashed
+	staleLockAge = 30 * time.Second
+)
+
+// ErrConfigChanged is returned when the config file was changed by another process after it was loaded
+var ErrConfigChanged = errors.New("the config file was changed by another process, run the command again")
+
+// Backup is a copy of the config file made before it was overwritten
+type Backup struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	path      string
+}
+
+// Backups returns the backups of the user config file, newest first
+func Backups() ([]Backup, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, err
+	}
+	return listBackups(path)
+}
+
+// RestoreBackup replaces the user config file with the named backup. The
+// current file is backed up first, so a restore can be rolled back too.
+func RestoreBackup(name string) error {
+	path, err := FilePath()
+	if err != nil {
+		return err
+	}
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		if backup.Name != name {
+			continue
+		}
+		contents, err := os.ReadFile(backup.path)
+		if err != nil {
+			return err
+		}
+		var cfg Config
+		if err := json.Unmarshal(contents, &cfg); err != nil {
+			return errors.Wrapf(err, "backup %s is not a valid config file", name)
+		}
+		_, err = writeFile(path, contents, "")
+		return err
+	}
+	return errors.Errorf("backup %s does not exist", name)
+}
+
+// writeFile replaces the file at path while holding its lock. The new contents are
+// written to a temporary file that's renamed over the old one, so the file is never
+// left partially written, and the old contents are backed up. Nothing is written if the
+// contents haven't changed. If expectedHash is set
+// and the file no longer matches it, nothing is written and ErrConfigChanged is returned.
+// It returns the hash of the new contents.
+func writeFile(path string, contents []byte, expectedHash string) (string, error) {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	if err == nil && bytes.Equal(current, contents) {
+		return hashContents(contents), nil
+	}
+	if expectedHash != "" && err == nil && hashContents(current) != expectedHash {
+		return "", ErrConfigChanged
+	}
+	if len(current) > 0 {
+		if err := backupFile(path, current); err != nil {
+			return "", errors.Wrap(err, "error backing up config file")
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		// Only left behind if something failed before the rename
+		_ = os.Remove(tmp.Name())
+	}()
+	_, err = tmp.Write(contents)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	// Temporary files are created with 0600, which also tightens permissions on
+	// config files created by older versions
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return "", err
+	}
+	return hashContents(contents), nil
+}
+
+// lockFile takes an exclusive lock on path by creating a lock file next to it, and
+// returns a function that releases it
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			info, statErr := f.Stat()
+			_ = f.Close()
+			return func() {
+				// Only remove the lock if it's still ours, not one taken after ours was removed as stale
+				if current, err := os.Stat(lockPath); err == nil && statErr == nil && os.SameFile(current, info) {
+					_ = os.Remove(lockPath)
+				}
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			removeStaleLock(lockPath, info)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Errorf("the config file is locked by another process (remove %s if no other workos process is running)", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// removeStaleLock removes the lock file at lockPath if it's still the stale lock that was
+// seen. The lock is renamed aside first, so a fresh lock another process created after
+// removing the same stale lock is put back rather than removed.
+func removeStaleLock(lockPath string, stale os.FileInfo) {
+	claimed := fmt.Sprintf("%s.%d", lockPath, os.Getpid())
+	if err := os.Rename(lockPath, claimed); err != nil {
+		return
+	}
+	if info, err := os.Stat(claimed); err == nil && !os.SameFile(info, stale) {
+		_ = os.Link(claimed, lockPath)
+	}
+	_ = os.Remove(claimed)
+}
+
+// backupFile saves contents as a timestamped backup of path and removes the oldest
+// backups so no more than MaxBackups are kept
+func backupFile(path string, contents []byte) error {
+	dir := filepath.Join(filepath.Dir(path), BackupDirName)
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	name := backupPrefix(path) + time.Now().UTC().Format(backupTimeFormat)
+	err = os.WriteFile(filepath.Join(dir, name), contents, 0600)
+	if err != nil {
+		return err
+	}
+
+	backups, err := listBackups(path)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(len(backups), MaxBackups):] {
+		_ = os.Remove(backup.path)
+	}
+	return nil
+}
+
+// listBackups returns the backups of path, newest first
+func listBackups(path string) ([]Backup, error) {
+	dir := filepath.Join(filepath.Dir(path), BackupDirName)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := backupPrefix(path)
+	backups := []Backup{}
+	for _, entry := range entries {
+		timestamp, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, timestamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Name: entry.Name(), CreatedAt: createdAt, path: filepath.Join(dir, entry.Name())})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// backupPrefix returns the start of the names of backups of path. The leading dot
+// of .workos.json is dropped so backups aren't hidden.
+func backupPrefix(path string) string {
+	return strings.TrimPrefix(filepath.Base(path), ".") + "."
+}
+
+func hashContents(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
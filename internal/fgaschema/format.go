@@ -0,0 +1,122 @@
+package fgaschema
+
+import (
+	"strings"
+)
+
+const indent = "    "
+
+// Format prints a schema in canonical form: four space indentation, a blank line
+// between definitions, relations before inherit blocks and policies after types.
+// Comments are kept with the definitions they precede.
+func Format(s *Schema) string {
+	f := &formatter{}
+	if s.Version != "" {
+		f.comments(0, s.Leading)
+		f.line(0, KeywordVersion+" "+s.Version, s.Line)
+	}
+
+	for _, t := range s.Types {
+		f.blank()
+		f.comments(0, t.Leading)
+		f.line(0, KeywordType+" "+t.Name, t.Line)
+		for _, relation := range t.Relations {
+			f.comments(1, relation.Leading)
+			text := KeywordRelation + " " + relation.Name
+			if len(relation.Subjects) > 0 {
+				text += " " + formatSubjectTypes(relation.Subjects)
+			}
+			f.line(1, text, relation.Line)
+		}
+		for _, inherit := range t.Inherits {
+			f.blank()
+			f.comments(1, inherit.Leading)
+			f.line(1, KeywordInherit+" "+inherit.Relation+" "+KeywordIf, inherit.Line)
+			f.rule(2, inherit.Rule)
+		}
+	}
+
+	for _, policy := range s.Policies {
+		f.blank()
+		f.comments(0, policy.Leading)
+		if len(policy.Body) == 0 {
+			f.line(0, KeywordPolicy+" "+policy.Signature+" {}", policy.Line)
+			continue
+		}
+		f.line(0, KeywordPolicy+" "+policy.Signature+" {", policy.Line)
+		for _, l := range policy.Body {
+			if strings.TrimSpace(l) == "" {
+				f.b.WriteString("\n")
+				continue
+			}
+			f.b.WriteString(indent + l + "\n")
+		}
+		f.b.WriteString("}\n")
+	}
+
+	if len(s.Trailing) > 0 {
+		f.blank()
+		f.comments(0, s.Trailing)
+	}
+	return f.b.String()
+}
+
+type formatter struct {
+	b strings.Builder
+}
+
+// blank separates definitions, but never at the start of the file
+func (f *formatter) blank() {
+	if f.b.Len() > 0 {
+		f.b.WriteString("\n")
+	}
+}
+
+func (f *formatter) line(depth int, text string, comment string) {
+	f.b.WriteString(strings.Repeat(indent, depth))
+	f.b.WriteString(text)
+	if comment != "" {
+		f.b.WriteString(" //" + comment)
+	}
+	f.b.WriteString("\n")
+}
+
+func (f *formatter) comments(depth int, comments []string) {
+	for _, comment := range comments {
+		f.b.WriteString(strings.Repeat(indent, depth) + "//" + comment + "\n")
+	}
+}
+
+func (f *formatter) rule(depth int, rule *Rule) {
+	f.comments(depth, rule.Leading)
+	f.line(depth, formatRule(rule), rule.Line)
+	for _, child := range rule.Rules {
+		f.rule(depth+1, child)
+	}
+}
+
+func formatRule(rule *Rule) string {
+	switch rule.Kind {
+	case RuleAnyOf:
+		return KeywordAnyOf
+	case RuleAllOf:
+		return KeywordAllOf
+	case RuleNoneOf:
+		return KeywordNoneOf
+	case RulePolicy:
+		return KeywordPolicy + " " + rule.Policy
+	}
+	text := KeywordRelation + " " + rule.Relation
+	if rule.On != "" {
+		text += " " + KeywordOn + " " + rule.On + " " + formatSubjectTypes(rule.OnTypes)
+	}
+	return text
+}
+
+func formatSubjectTypes(subjects []SubjectType) string {
+	var parts []string
+	for _, subject := range subjects {
+		parts = append(parts, subject.String())
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
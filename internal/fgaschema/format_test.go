@@ -0,0 +1,60 @@
+package fgaschema
+
+import (
+	"testing"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	s, err := Parse(exampleSchema)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := Format(s); got != exampleSchema {
+		t.Errorf("Format() changed a formatted schema:\n%s", got)
+	}
+}
+
+func TestFormatCanonical(t *testing.T) {
+	src := "version 0.2\n" +
+		"type doc\n" +
+		"\tinherit viewer if relation owner\n" +
+		"  relation owner [user]\n" +
+		"  relation viewer\n" +
+		"policy open(x string) { x == \"y\" }\n" +
+		"type user\n" +
+		"// trailing\n"
+	want := `version 0.2
+
+type doc
+    relation owner [user]
+    relation viewer
+
+    inherit viewer if
+        relation owner
+
+type user
+
+policy open(x string) {
+    x == "y"
+}
+
+// trailing
+`
+	s, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got := Format(s)
+	if got != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Formatting is idempotent
+	s, err = Parse(got)
+	if err != nil {
+		t.Fatalf("Parse() of formatted schema error = %v", err)
+	}
+	if again := Format(s); again != got {
+		t.Errorf("Format() of formatted schema =\n%s\nwant\n%s", again, got)
+	}
+}
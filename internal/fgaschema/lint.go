@@ -0,0 +1,232 @@
+package fgaschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a schema
+type Diagnostic struct {
+	Pos      Pos
+	Severity Severity
+	Message  string
+}
+
+// Lint checks a parsed schema for undefined types, relations and policies, relations
+// that are defined more than once, inheritance cycles and unused types.
+// Diagnostics are sorted by position.
+func Lint(s *Schema) []Diagnostic {
+	l := &linter{types: map[string]*Type{}, policies: map[string]*Policy{}}
+	if s.Version == "" {
+		l.report(Pos{Line: 1, Column: 1}, SeverityError, "missing version")
+	}
+
+	for _, t := range s.Types {
+		if existing, ok := l.types[t.Name]; ok {
+			l.report(t.Pos, SeverityError, fmt.Sprintf("type %s is already defined at %s", t.Name, existing.Pos))
+			continue
+		}
+		l.types[t.Name] = t
+	}
+
+	for _, policy := range s.Policies {
+		name := policy.Name()
+		if existing, ok := l.policies[name]; ok {
+			l.report(policy.Pos, SeverityError, fmt.Sprintf("policy %s is already defined at %s", name, existing.Pos))
+			continue
+		}
+		l.policies[name] = policy
+	}
+
+	referenced := map[string]bool{}
+	for _, t := range s.Types {
+		relations := map[string]*Relation{}
+		for _, relation := range t.Relations {
+			if existing, ok := relations[relation.Name]; ok {
+				l.report(relation.Pos, SeverityError, fmt.Sprintf("relation %s shadows the relation defined at %s", relation.Name, existing.Pos))
+				continue
+			}
+			relations[relation.Name] = relation
+			for _, subject := range relation.Subjects {
+				referenced[subject.Type] = true
+				l.checkSubjectType(subject)
+			}
+		}
+
+		for _, inherit := range t.Inherits {
+			if t.Relation(inherit.Relation) == nil {
+				l.report(inherit.Pos, SeverityError, fmt.Sprintf("undefined relation %s on type %s", inherit.Relation, t.Name))
+			}
+			l.checkRule(t, inherit.Rule, referenced)
+		}
+		l.checkCycles(t)
+	}
+
+	for _, t := range s.Types {
+		if len(t.Relations) == 0 && !referenced[t.Name] {
+			l.report(t.Pos, SeverityWarning, fmt.Sprintf("type %s has no relations and is never used as a subject", t.Name))
+		}
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i].Pos, l.diagnostics[j].Pos
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.diagnostics
+}
+
+type linter struct {
+	types       map[string]*Type
+	policies    map[string]*Policy
+	diagnostics []Diagnostic
+}
+
+func (l *linter) report(pos Pos, severity Severity, msg string) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{Pos: pos, Severity: severity, Message: msg})
+}
+
+// checkSubjectType checks that a subject type, and its relation if it has one, are defined
+func (l *linter) checkSubjectType(subject SubjectType) {
+	t, ok := l.types[subject.Type]
+	if !ok {
+		l.report(subject.Pos, SeverityError, fmt.Sprintf("undefined type %s", subject.Type))
+		return
+	}
+	if subject.Relation != "" && t.Relation(subject.Relation) == nil {
+		l.report(subject.Pos, SeverityError, fmt.Sprintf("undefined relation %s on type %s", subject.Relation, subject.Type))
+	}
+}
+
+func (l *linter) checkRule(t *Type, rule *Rule, referenced map[string]bool) {
+	if rule == nil {
+		return
+	}
+	for _, child := range rule.Rules {
+		l.checkRule(t, child, referenced)
+	}
+	if rule.Kind == RulePolicy {
+		if _, ok := l.policies[rule.Policy]; !ok {
+			l.report(rule.Pos, SeverityError, fmt.Sprintf("undefined policy %s", rule.Policy))
+		}
+		return
+	}
+	if rule.Kind != RuleRelation {
+		return
+	}
+
+	if rule.On == "" {
+		if t.Relation(rule.Relation) == nil {
+			l.report(rule.Pos, SeverityError, fmt.Sprintf("undefined relation %s on type %s", rule.Relation, t.Name))
+		}
+		return
+	}
+
+	on := t.Relation(rule.On)
+	if on == nil {
+		l.report(rule.Pos, SeverityError, fmt.Sprintf("undefined relation %s on type %s", rule.On, t.Name))
+	}
+	for _, onType := range rule.OnTypes {
+		referenced[onType.Type] = true
+		related, ok := l.types[onType.Type]
+		if !ok {
+			l.report(onType.Pos, SeverityError, fmt.Sprintf("undefined type %s", onType.Type))
+			continue
+		}
+		if related.Relation(rule.Relation) == nil {
+			l.report(onType.Pos, SeverityError, fmt.Sprintf("undefined relation %s on type %s", rule.Relation, onType.Type))
+		}
+		if on != nil && len(on.Subjects) > 0 && !allowsType(on, onType.Type) {
+			l.report(onType.Pos, SeverityWarning, fmt.Sprintf("relation %s on type %s does not allow subjects of type %s", rule.On, t.Name, onType.Type))
+		}
+	}
+}
+
+// checkCycles reports relations of a type that inherit from themselves. Inheriting
+// through a relation on another object (e.g. a parent folder) is not a cycle.
+func (l *linter) checkCycles(t *Type) {
+	edges := map[string][]string{}
+	positions := map[string]Pos{}
+	var order []string
+	for _, inherit := range t.Inherits {
+		if _, ok := edges[inherit.Relation]; !ok {
+			order = append(order, inherit.Relation)
+			positions[inherit.Relation] = inherit.Pos
+		}
+		edges[inherit.Relation] = append(edges[inherit.Relation], directRelations(inherit.Rule)...)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	reported := map[string]bool{}
+	var stack []string
+	var visit func(relation string)
+	visit = func(relation string) {
+		state[relation] = visiting
+		stack = append(stack, relation)
+		for _, next := range edges[relation] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != next {
+					start--
+				}
+				cycle := append(append([]string{}, stack[start:]...), next)
+				members := append([]string{}, stack[start:]...)
+				sort.Strings(members)
+				key := strings.Join(members, ",")
+				if !reported[key] {
+					reported[key] = true
+					l.report(positions[next], SeverityError, fmt.Sprintf("inheritance cycle on type %s: %s", t.Name, strings.Join(cycle, " -> ")))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[relation] = done
+	}
+	for _, relation := range order {
+		if state[relation] == unvisited {
+			visit(relation)
+		}
+	}
+}
+
+// directRelations returns the relations on the same object that a rule inherits from
+func directRelations(rule *Rule) []string {
+	if rule == nil {
+		return nil
+	}
+	var relations []string
+	if rule.Kind == RuleRelation && rule.On == "" {
+		relations = append(relations, rule.Relation)
+	}
+	for _, child := range rule.Rules {
+		relations = append(relations, directRelations(child)...)
+	}
+	return relations
+}
+
+func allowsType(relation *Relation, typeName string) bool {
+	for _, subject := range relation.Subjects {
+		if subject.Type == typeName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package fgaschema
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []Diagnostic
+	}{
+		{
+			name: "valid schema",
+			src:  exampleSchema,
+		},
+		{
+			name: "missing version",
+			src:  "type user\ntype doc\n    relation owner [user]\n",
+			want: []Diagnostic{{Pos: Pos{Line: 1, Column: 1}, Severity: SeverityError, Message: "missing version"}},
+		},
+		{
+			name: "undefined type and subject relation",
+			src:  "version 0.2\ntype team\n    relation member [user]\ntype doc\n    relation owner [team#admin]\n",
+			want: []Diagnostic{
+				{Pos: Pos{Line: 3, Column: 22}, Severity: SeverityError, Message: "undefined type user"},
+				{Pos: Pos{Line: 5, Column: 21}, Severity: SeverityError, Message: "undefined relation admin on type team"},
+			},
+		},
+		{
+			name: "duplicate relation",
+			src:  "version 0.2\ntype doc\n    relation owner\n    relation owner\n",
+			want: []Diagnostic{{Pos: Pos{Line: 4, Column: 14}, Severity: SeverityError, Message: "relation owner shadows the relation defined at 3:14"}},
+		},
+		{
+			name: "inheritance cycle",
+			src:  "version 0.2\ntype doc\n    relation a\n    relation b\n    inherit a if relation b\n    inherit b if relation a\n",
+			want: []Diagnostic{{Pos: Pos{Line: 5, Column: 13}, Severity: SeverityError, Message: "inheritance cycle on type doc: a -> b -> a"}},
+		},
+		{
+			name: "inheriting through another object is not a cycle",
+			src:  "version 0.2\ntype folder\n    relation viewer\n    relation parent [folder]\n    inherit viewer if relation viewer on parent [folder]\n",
+		},
+		{
+			name: "undefined policy",
+			src:  "version 0.2\ntype doc\n    relation viewer\n    inherit viewer if\n        policy is_public\n",
+			want: []Diagnostic{{Pos: Pos{Line: 5, Column: 9}, Severity: SeverityError, Message: "undefined policy is_public"}},
+		},
+		{
+			name: "duplicate policy",
+			src:  "version 0.2\ntype doc\n    relation viewer\npolicy open(x string) {}\npolicy open(y string) {}\n",
+			want: []Diagnostic{{Pos: Pos{Line: 5, Column: 1}, Severity: SeverityError, Message: "policy open is already defined at 4:1"}},
+		},
+		{
+			name: "unused type",
+			src:  "version 0.2\ntype user\ntype doc\n    relation viewer\n",
+			want: []Diagnostic{{Pos: Pos{Line: 2, Column: 6}, Severity: SeverityWarning, Message: "type user has no relations and is never used as a subject"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.src)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got := Lint(s)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Lint()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
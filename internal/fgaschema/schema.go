@@ -0,0 +1,587 @@
+// Package fgaschema parses, formats and lints FGA schemas without calling the API.
+package fgaschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	KeywordAllOf    = "all_of"
+	KeywordAnyOf    = "any_of"
+	KeywordIf       = "if"
+	KeywordInherit  = "inherit"
+	KeywordNoneOf   = "none_of"
+	KeywordOn       = "on"
+	KeywordPolicy   = "policy"
+	KeywordRelation = "relation"
+	KeywordType     = "type"
+	KeywordVersion  = "version"
+
+	tabWidth = 4
+)
+
+// Pos is a 1-based line and column in a schema
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Comments are the comment lines before a node and the comment at the end of its line
+type Comments struct {
+	Leading []string
+	Line    string
+}
+
+type Schema struct {
+	Comments
+	Version    string
+	VersionPos Pos
+	Types      []*Type
+	Policies   []*Policy
+	// Trailing holds comments after the last definition
+	Trailing []string
+}
+
+type Type struct {
+	Comments
+	Name      string
+	Pos       Pos
+	Relations []*Relation
+	Inherits  []*Inherit
+}
+
+// Relation returns the type's first relation with the given name
+func (t *Type) Relation(name string) *Relation {
+	for _, relation := range t.Relations {
+		if relation.Name == name {
+			return relation
+		}
+	}
+	return nil
+}
+
+type Relation struct {
+	Comments
+	Name     string
+	Pos      Pos
+	Subjects []SubjectType
+}
+
+// SubjectType is a type allowed as the subject of a relation, optionally a relation on that type (e.g. team#member)
+type SubjectType struct {
+	Type     string
+	Relation string
+	Pos      Pos
+}
+
+func (s SubjectType) String() string {
+	if s.Relation != "" {
+		return s.Type + "#" + s.Relation
+	}
+	return s.Type
+}
+
+type Inherit struct {
+	Comments
+	Relation string
+	Pos      Pos
+	Rule     *Rule
+}
+
+type RuleKind int
+
+const (
+	RuleRelation RuleKind = iota
+	RulePolicy
+	RuleAnyOf
+	RuleAllOf
+	RuleNoneOf
+)
+
+// Rule is a condition for inheriting a relation. Relation rules may inherit through
+// a relation on another object (relation viewer on parent [folder]).
+type Rule struct {
+	Comments
+	Kind     RuleKind
+	Pos      Pos
+	Relation string
+	On       string
+	OnTypes  []SubjectType
+	Policy   string
+	Rules    []*Rule
+}
+
+// Policy is a policy definition. Its body is kept as written.
+type Policy struct {
+	Comments
+	Signature string
+	Body      []string
+	Pos       Pos
+}
+
+// Name returns the policy's name, the part of its signature before the arguments
+func (p *Policy) Name() string {
+	name, _, _ := strings.Cut(p.Signature, "(")
+	return strings.TrimSpace(name)
+}
+
+// Error is a syntax error at a position in a schema
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+type line struct {
+	num        int
+	indent     int
+	col        int
+	text       string
+	raw        string
+	comment    string
+	hasComment bool
+}
+
+type token struct {
+	text string
+	col  int
+}
+
+type parser struct {
+	lines   []line
+	i       int
+	pending []string
+}
+
+// Parse parses a schema, returning an *Error for the first syntax error
+func Parse(src string) (*Schema, error) {
+	p := &parser{}
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		p.lines = append(p.lines, splitLine(i+1, raw))
+	}
+
+	schema := &Schema{}
+	var current *Type
+	for {
+		l, ok := p.peek()
+		if !ok {
+			break
+		}
+		p.i++
+
+		// Policy definitions have their own syntax, so they're parsed before tokenizing
+		if keyword, _, _ := strings.Cut(l.text, " "); keyword == KeywordPolicy && l.indent == 0 {
+			policy, err := p.parsePolicy(l)
+			if err != nil {
+				return nil, err
+			}
+			schema.Policies = append(schema.Policies, policy)
+			current = nil
+			continue
+		}
+		toks, err := tokenize(l)
+		if err != nil {
+			return nil, err
+		}
+
+		switch toks[0].text {
+		case KeywordVersion:
+			if schema.Version != "" {
+				return nil, errorAt(l, toks[0], "version is already set")
+			}
+			if len(toks) != 2 {
+				return nil, errorAt(l, toks[0], "expected version <number>")
+			}
+			schema.Comments = p.comments(l)
+			schema.Version = toks[1].text
+			schema.VersionPos = pos(l, toks[0])
+		case KeywordType:
+			if len(toks) != 2 || !isIdent(toks[1].text) {
+				return nil, errorAt(l, toks[0], "expected type <name>")
+			}
+			current = &Type{Comments: p.comments(l), Name: toks[1].text, Pos: pos(l, toks[1])}
+			schema.Types = append(schema.Types, current)
+		case KeywordPolicy:
+			return nil, errorAt(l, toks[0], "policy rules must be part of an inherit block")
+		case KeywordRelation:
+			if current == nil {
+				return nil, errorAt(l, toks[0], "relation must be defined within a type")
+			}
+			relation, err := parseRelation(l, toks)
+			if err != nil {
+				return nil, err
+			}
+			relation.Comments = p.comments(l)
+			current.Relations = append(current.Relations, relation)
+		case KeywordInherit:
+			if current == nil {
+				return nil, errorAt(l, toks[0], "inherit must be defined within a type")
+			}
+			inherit, err := p.parseInherit(l, toks)
+			if err != nil {
+				return nil, err
+			}
+			current.Inherits = append(current.Inherits, inherit)
+		default:
+			return nil, errorAt(l, toks[0], fmt.Sprintf("unexpected %q", toks[0].text))
+		}
+	}
+	schema.Trailing = p.pending
+	return schema, nil
+}
+
+// peek skips blank and comment lines, collecting comments, and returns the next line with content
+func (p *parser) peek() (line, bool) {
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.text != "" {
+			return l, true
+		}
+		if l.hasComment {
+			p.pending = append(p.pending, l.comment)
+		}
+		p.i++
+	}
+	return line{}, false
+}
+
+// comments returns the comments collected for the node on the given line
+func (p *parser) comments(l line) Comments {
+	c := Comments{Leading: p.pending, Line: l.comment}
+	p.pending = nil
+	return c
+}
+
+func parseRelation(l line, toks []token) (*Relation, error) {
+	if len(toks) < 2 || !isIdent(toks[1].text) {
+		return nil, errorAt(l, toks[0], "expected relation <name> [<types>]")
+	}
+	relation := &Relation{Name: toks[1].text, Pos: pos(l, toks[1])}
+	if len(toks) == 2 {
+		return relation, nil
+	}
+	subjects, rest, err := parseSubjectTypes(l, toks[2:])
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errorAt(l, rest[0], fmt.Sprintf("unexpected %q", rest[0].text))
+	}
+	relation.Subjects = subjects
+	return relation, nil
+}
+
+// parseSubjectTypes parses a list of the form [type, type#relation] and returns the remaining tokens
+func parseSubjectTypes(l line, toks []token) ([]SubjectType, []token, error) {
+	if toks[0].text != "[" {
+		return nil, nil, errorAt(l, toks[0], "expected [")
+	}
+	var subjects []SubjectType
+	i := 1
+	for {
+		if i >= len(toks) {
+			return nil, nil, errorAt(l, toks[len(toks)-1], "expected ]")
+		}
+		if toks[i].text == "]" && len(subjects) == 0 {
+			return nil, nil, errorAt(l, toks[i], "expected at least one type")
+		}
+		if !isIdent(toks[i].text) {
+			return nil, nil, errorAt(l, toks[i], fmt.Sprintf("expected a type, got %q", toks[i].text))
+		}
+		subject := SubjectType{Type: toks[i].text, Pos: pos(l, toks[i])}
+		i++
+		if i+1 < len(toks) && toks[i].text == "#" {
+			if !isIdent(toks[i+1].text) {
+				return nil, nil, errorAt(l, toks[i+1], "expected a relation")
+			}
+			subject.Relation = toks[i+1].text
+			i += 2
+		}
+		subjects = append(subjects, subject)
+
+		if i >= len(toks) {
+			return nil, nil, errorAt(l, toks[i-1], "expected ]")
+		}
+		switch toks[i].text {
+		case ",":
+			i++
+		case "]":
+			return subjects, toks[i+1:], nil
+		default:
+			return nil, nil, errorAt(l, toks[i], fmt.Sprintf("expected , or ], got %q", toks[i].text))
+		}
+	}
+}
+
+func (p *parser) parseInherit(l line, toks []token) (*Inherit, error) {
+	if len(toks) < 3 || !isIdent(toks[1].text) || toks[2].text != KeywordIf {
+		return nil, errorAt(l, toks[0], "expected inherit <relation> if")
+	}
+	inherit := &Inherit{Comments: p.comments(l), Relation: toks[1].text, Pos: pos(l, toks[1])}
+
+	// The rule can start on the same line (inherit viewer if relation editor)
+	if len(toks) > 3 {
+		rule, err := parseRule(l, toks[3:])
+		if err != nil {
+			return nil, err
+		}
+		if isGroup(rule.Kind) {
+			rule.Rules, err = p.parseRules(l.indent)
+			if err != nil {
+				return nil, err
+			}
+			if len(rule.Rules) == 0 {
+				return nil, errorAt(l, toks[3], fmt.Sprintf("%s needs at least one rule", toks[3].text))
+			}
+		}
+		inherit.Rule = rule
+		if next, ok := p.peek(); ok && next.indent > l.indent && !isGroup(rule.Kind) {
+			return nil, &Error{Pos: Pos{Line: next.num, Column: next.col}, Msg: "unexpected indentation"}
+		}
+		return inherit, nil
+	}
+
+	rules, err := p.parseRules(l.indent)
+	if err != nil {
+		return nil, err
+	}
+	switch len(rules) {
+	case 0:
+		return nil, errorAt(l, toks[2], "expected a rule")
+	case 1:
+		inherit.Rule = rules[0]
+	default:
+		return nil, &Error{Pos: rules[1].Pos, Msg: "inherit takes a single rule, combine rules with any_of, all_of or none_of"}
+	}
+	return inherit, nil
+}
+
+// parseRules parses the rules indented under a line with the given indentation
+func (p *parser) parseRules(parentIndent int) ([]*Rule, error) {
+	var rules []*Rule
+	level := -1
+	for {
+		l, ok := p.peek()
+		if !ok || l.indent <= parentIndent {
+			return rules, nil
+		}
+		toks, err := tokenize(l)
+		if err != nil {
+			return nil, err
+		}
+		if toks[0].text == KeywordType || toks[0].text == KeywordInherit || toks[0].text == KeywordVersion {
+			return rules, nil
+		}
+		if level == -1 {
+			level = l.indent
+		} else if l.indent != level {
+			return nil, errorAt(l, toks[0], "unexpected indentation")
+		}
+		p.i++
+
+		rule, err := parseRule(l, toks)
+		if err != nil {
+			return nil, err
+		}
+		rule.Comments = p.comments(l)
+		if isGroup(rule.Kind) {
+			rule.Rules, err = p.parseRules(l.indent)
+			if err != nil {
+				return nil, err
+			}
+			if len(rule.Rules) == 0 {
+				return nil, errorAt(l, toks[0], fmt.Sprintf("%s needs at least one rule", toks[0].text))
+			}
+		}
+		rules = append(rules, rule)
+	}
+}
+
+func parseRule(l line, toks []token) (*Rule, error) {
+	rule := &Rule{Pos: pos(l, toks[0])}
+	switch toks[0].text {
+	case KeywordAnyOf, KeywordAllOf, KeywordNoneOf:
+		if len(toks) > 1 {
+			return nil, errorAt(l, toks[1], fmt.Sprintf("unexpected %q, %s rules go on the following lines", toks[1].text, toks[0].text))
+		}
+		rule.Kind = map[string]RuleKind{KeywordAnyOf: RuleAnyOf, KeywordAllOf: RuleAllOf, KeywordNoneOf: RuleNoneOf}[toks[0].text]
+	case KeywordPolicy:
+		if len(toks) != 2 || !isIdent(toks[1].text) {
+			return nil, errorAt(l, toks[0], "expected policy <name>")
+		}
+		rule.Kind = RulePolicy
+		rule.Policy = toks[1].text
+	case KeywordRelation:
+		if len(toks) < 2 || !isIdent(toks[1].text) {
+			return nil, errorAt(l, toks[0], "expected relation <name>")
+		}
+		rule.Kind = RuleRelation
+		rule.Relation = toks[1].text
+		if len(toks) == 2 {
+			break
+		}
+		if toks[2].text != KeywordOn || len(toks) < 5 || !isIdent(toks[3].text) {
+			return nil, errorAt(l, toks[2], "expected relation <name> on <relation> [<types>]")
+		}
+		rule.On = toks[3].text
+		onTypes, rest, err := parseSubjectTypes(l, toks[4:])
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 0 {
+			return nil, errorAt(l, rest[0], fmt.Sprintf("unexpected %q", rest[0].text))
+		}
+		rule.OnTypes = onTypes
+	default:
+		return nil, errorAt(l, toks[0], fmt.Sprintf("expected a rule (relation, policy, any_of, all_of or none_of), got %q", toks[0].text))
+	}
+	return rule, nil
+}
+
+// parsePolicy parses a policy definition, keeping its body as written
+func (p *parser) parsePolicy(l line) (*Policy, error) {
+	policy := &Policy{Comments: p.comments(l), Pos: Pos{Line: l.num, Column: l.col}}
+	// Policy bodies can contain //, so the header is read from the raw line
+	policy.Line = ""
+	header := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(l.raw), KeywordPolicy))
+	signature, body, ok := strings.Cut(header, "{")
+	if !ok {
+		return nil, &Error{Pos: policy.Pos, Msg: "expected policy <name>(<args>) {"}
+	}
+	policy.Signature = strings.TrimSpace(signature)
+	if policy.Signature == "" {
+		return nil, &Error{Pos: policy.Pos, Msg: "expected policy <name>(<args>) {"}
+	}
+
+	// Single line policies: policy name(args) { expression }
+	if body = strings.TrimSpace(body); strings.HasSuffix(body, "}") {
+		if expression := strings.TrimSpace(strings.TrimSuffix(body, "}")); expression != "" {
+			policy.Body = []string{expression}
+		}
+		return policy, nil
+	}
+	if body != "" {
+		policy.Body = append(policy.Body, body)
+	}
+
+	var lines []string
+	for ; p.i < len(p.lines); p.i++ {
+		raw := p.lines[p.i].raw
+		if strings.TrimSpace(raw) == "}" {
+			p.i++
+			policy.Body = append(policy.Body, dedent(lines)...)
+			return policy, nil
+		}
+		lines = append(lines, raw)
+	}
+	return nil, &Error{Pos: policy.Pos, Msg: "policy is missing a closing }"}
+}
+
+// splitLine separates a line's indentation, content and end of line comment
+func splitLine(num int, raw string) line {
+	l := line{num: num, raw: strings.TrimRight(raw, " \t")}
+	text := l.raw
+	if before, comment, ok := strings.Cut(text, "//"); ok {
+		text = before
+		l.comment = strings.TrimRight(comment, " \t")
+		l.hasComment = true
+	}
+	for i, r := range text {
+		if r == ' ' {
+			l.indent++
+		} else if r == '\t' {
+			l.indent += tabWidth
+		} else {
+			l.col = i + 1
+			l.text = strings.TrimSpace(text[i:])
+			break
+		}
+	}
+	return l
+}
+
+func tokenize(l line) ([]token, error) {
+	var toks []token
+	src := l.text
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '[' || c == ']' || c == ',' || c == '#':
+			toks = append(toks, token{text: string(c), col: l.col + i})
+			i++
+		case isIdentChar(c):
+			start := i
+			for i < len(src) && isIdentChar(src[i]) {
+				i++
+			}
+			toks = append(toks, token{text: src[start:i], col: l.col + start})
+		default:
+			return nil, &Error{Pos: Pos{Line: l.num, Column: l.col + i}, Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return toks, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isIdent(s string) bool {
+	switch s {
+	case KeywordAllOf, KeywordAnyOf, KeywordIf, KeywordInherit, KeywordNoneOf, KeywordOn, KeywordPolicy, KeywordRelation, KeywordType, KeywordVersion:
+		return false
+	}
+	return s != "" && isIdentChar(s[0])
+}
+
+func isGroup(kind RuleKind) bool {
+	return kind == RuleAnyOf || kind == RuleAllOf || kind == RuleNoneOf
+}
+
+func pos(l line, t token) Pos {
+	return Pos{Line: l.num, Column: t.col}
+}
+
+func errorAt(l line, t token, msg string) *Error {
+	return &Error{Pos: pos(l, t), Msg: msg}
+}
+
+// dedent removes the indentation shared by all non-blank lines and surrounding blank lines
+func dedent(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		if first {
+			prefix = indent
+			first = false
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	var out []string
+	for _, l := range lines {
+		out = append(out, strings.TrimPrefix(l, prefix))
+	}
+	return out
+}
@@ -0,0 +1,154 @@
+package fgaschema
+
+import (
+	"errors"
+	"testing"
+)
+
+const exampleSchema = `version 0.2
+
+// users
+type user
+
+type team
+    relation member [user]
+
+type document
+    relation owner [user, team#member] // owners
+    relation viewer [user]
+    relation parent [document]
+
+    inherit viewer if
+        any_of
+            relation owner
+            relation viewer on parent [document]
+            policy is_public
+
+policy is_public(visibility string) {
+    visibility == "public"
+}
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse(exampleSchema)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if s.Version != "0.2" {
+		t.Errorf("Version = %q, want 0.2", s.Version)
+	}
+	if len(s.Types) != 3 {
+		t.Fatalf("got %d types, want 3", len(s.Types))
+	}
+	if got := s.Types[0].Leading; len(got) != 1 || got[0] != " users" {
+		t.Errorf("user comments = %q, want [\" users\"]", got)
+	}
+
+	document := s.Types[2]
+	owner := document.Relation("owner")
+	if owner == nil {
+		t.Fatal("document has no owner relation")
+	}
+	if owner.Line != " owners" {
+		t.Errorf("owner line comment = %q, want \" owners\"", owner.Line)
+	}
+	if len(owner.Subjects) != 2 || owner.Subjects[1].String() != "team#member" {
+		t.Errorf("owner subjects = %v, want [user team#member]", owner.Subjects)
+	}
+	if owner.Pos != (Pos{Line: 10, Column: 14}) {
+		t.Errorf("owner position = %s, want 10:14", owner.Pos)
+	}
+
+	if len(document.Inherits) != 1 {
+		t.Fatalf("got %d inherits, want 1", len(document.Inherits))
+	}
+	rule := document.Inherits[0].Rule
+	if rule.Kind != RuleAnyOf || len(rule.Rules) != 3 {
+		t.Fatalf("inherit rule = %+v, want any_of with 3 rules", rule)
+	}
+	if on := rule.Rules[1]; on.Relation != "viewer" || on.On != "parent" || len(on.OnTypes) != 1 || on.OnTypes[0].Type != "document" {
+		t.Errorf("second rule = %+v, want relation viewer on parent [document]", on)
+	}
+	if policy := rule.Rules[2]; policy.Kind != RulePolicy || policy.Policy != "is_public" {
+		t.Errorf("third rule = %+v, want policy is_public", policy)
+	}
+
+	if len(s.Policies) != 1 {
+		t.Fatalf("got %d policies, want 1", len(s.Policies))
+	}
+	if name := s.Policies[0].Name(); name != "is_public" {
+		t.Errorf("policy name = %q, want is_public", name)
+	}
+	if body := s.Policies[0].Body; len(body) != 1 || body[0] != `visibility == "public"` {
+		t.Errorf("policy body = %q", body)
+	}
+}
+
+func TestParseInlineRule(t *testing.T) {
+	s, err := Parse("version 0.2\ntype doc\n    relation editor\n    relation viewer\n    inherit viewer if relation editor\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	rule := s.Types[0].Inherits[0].Rule
+	if rule.Kind != RuleRelation || rule.Relation != "editor" {
+		t.Errorf("rule = %+v, want relation editor", rule)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		pos  Pos
+		msg  string
+	}{
+		{
+			name: "unclosed subject types",
+			src:  "version 0.2\ntype doc\n    relation owner [user\n",
+			pos:  Pos{Line: 3, Column: 21},
+			msg:  "expected ]",
+		},
+		{
+			name: "relation outside a type",
+			src:  "version 0.2\nrelation owner\n",
+			pos:  Pos{Line: 2, Column: 1},
+			msg:  "relation must be defined within a type",
+		},
+		{
+			name: "duplicate version",
+			src:  "version 0.2\nversion 0.3\n",
+			pos:  Pos{Line: 2, Column: 1},
+			msg:  "version is already set",
+		},
+		{
+			name: "empty group",
+			src:  "version 0.2\ntype doc\n    relation viewer\n    inherit viewer if any_of\n",
+			pos:  Pos{Line: 4, Column: 23},
+			msg:  "any_of needs at least one rule",
+		},
+		{
+			name: "unexpected character",
+			src:  "version 0.2\ntype doc!\n",
+			pos:  Pos{Line: 2, Column: 9},
+			msg:  `unexpected character '!'`,
+		},
+		{
+			name: "unclosed policy",
+			src:  "version 0.2\npolicy open(x string) {\n    x == \"y\"\n",
+			pos:  Pos{Line: 2, Column: 1},
+			msg:  "policy is missing a closing }",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.src)
+			var parseErr *Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Parse() error = %v, want *Error", err)
+			}
+			if parseErr.Pos != tt.pos || parseErr.Msg != tt.msg {
+				t.Errorf("Parse() error = %s: %s, want %s: %s", parseErr.Pos, parseErr.Msg, tt.pos, tt.msg)
+			}
+		})
+	}
+}
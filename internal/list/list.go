@@ -1,8 +1,123 @@
 package list
 
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/workos/workos-go/v4/pkg/common"
+)
+
 const (
-	FlagAfter  = "after"
-	FlagBefore = "before"
-	FlagLimit  = "limit"
-	FlagOrder  = "order"
+	FlagAfter    = "after"
+	FlagAll      = "all"
+	FlagBefore   = "before"
+	FlagLimit    = "limit"
+	FlagMaxItems = "max-items"
+	FlagOrder    = "order"
+
+	// MaxPageSize is the largest page size accepted by list endpoints. It is
+	// used as the page size when walking every page unless --limit is set.
+	MaxPageSize = 100
 )
+
+// Options controls how many pages of a list are fetched
+type Options struct {
+	After    string
+	All      bool
+	MaxItems int
+}
+
+// AddFlags registers the cursor, limit and order flags along with the flags
+// used to fetch every page of a list
+func AddFlags(flags *pflag.FlagSet) {
+	flags.String(FlagAfter, "", "Cursor for results after a specific item")
+	flags.String(FlagBefore, "", "Cursor for results before a specific item")
+	flags.Int(FlagLimit, 0, "Limit the number of results")
+	flags.String(FlagOrder, "", "Order of results (asc or desc)")
+	AddAllFlags(flags)
+}
+
+// AddAllFlags registers the flags used to fetch every page of a list
+func AddAllFlags(flags *pflag.FlagSet) {
+	flags.Bool(FlagAll, false, "Fetch all pages of results by following the after cursor")
+	flags.Int(FlagMaxItems, 0, "Maximum number of results to fetch across all pages (implies --all, so no cursor is printed)")
+}
+
+// GetOptions reads the pagination flags registered by AddAllFlags along with
+// the after/before/limit flags. It returns the page size to request, which
+// defaults to MaxPageSize when fetching all pages without an explicit limit.
+func GetOptions(flags *pflag.FlagSet) (Options, int, error) {
+	after, err := flags.GetString(FlagAfter)
+	if err != nil {
+		return Options{}, 0, errors.New("invalid after flag")
+	}
+	before, err := flags.GetString(FlagBefore)
+	if err != nil {
+		return Options{}, 0, errors.New("invalid before flag")
+	}
+	limit, err := flags.GetInt(FlagLimit)
+	if err != nil {
+		return Options{}, 0, errors.New("invalid limit flag")
+	}
+	all, err := flags.GetBool(FlagAll)
+	if err != nil {
+		return Options{}, 0, errors.New("invalid all flag")
+	}
+	maxItems, err := flags.GetInt(FlagMaxItems)
+	if err != nil {
+		return Options{}, 0, errors.New("invalid max-items flag")
+	}
+	if maxItems < 0 {
+		return Options{}, 0, errors.New("max-items must not be negative")
+	}
+
+	opts := Options{
+		After:    after,
+		All:      all || maxItems > 0,
+		MaxItems: maxItems,
+	}
+	if opts.All {
+		if before != "" {
+			return Options{}, 0, errors.New("--before cannot be used with --all")
+		}
+		if !flags.Changed(FlagLimit) {
+			limit = MaxPageSize
+		}
+	}
+	return opts, limit, nil
+}
+
+// Walk fetches a single page starting at opts.After or, when opts.All is set,
+// follows after cursors until the results are exhausted or opts.MaxItems items
+// have been passed to yield. It returns the list metadata of the last page fetched.
+// When opts.MaxItems stops partway through a page, the metadata's After cursor still
+// points past the whole page, so it's only meaningful to resume from without MaxItems.
+// Commands don't print cursors when fetching all pages for this reason.
+func Walk[T any](opts Options, fetch func(after string) ([]T, common.ListMetadata, error), yield func(T) error) (common.ListMetadata, error) {
+	after := opts.After
+	count := 0
+	for {
+		items, metadata, err := fetch(after)
+		if err != nil {
+			return metadata, err
+		}
+
+		for _, item := range items {
+			if opts.MaxItems > 0 && count >= opts.MaxItems {
+				return metadata, nil
+			}
+			err = yield(item)
+			if err != nil {
+				return metadata, err
+			}
+			count++
+		}
+
+		if !opts.All || metadata.After == "" || len(items) == 0 {
+			return metadata, nil
+		}
+		if opts.MaxItems > 0 && count >= opts.MaxItems {
+			return metadata, nil
+		}
+		after = metadata.After
+	}
+}
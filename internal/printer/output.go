@@ -0,0 +1,236 @@
+package printer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatTable  = "table"
+	FormatJson   = "json"
+	FormatYaml   = "yaml"
+	FormatCsv    = "csv"
+	FormatNdjson = "ndjson"
+)
+
+var Formats = []string{FormatTable, FormatJson, FormatYaml, FormatCsv, FormatNdjson}
+
+var format = FormatTable
+
+// SetFormat sets the output format used by Print, PrintResult and ListPrinter
+func SetFormat(f string) error {
+	for _, supported := range Formats {
+		if f == supported {
+			format = f
+			return nil
+		}
+	}
+	return errors.Errorf("invalid output format: %s (must be one of %s)", f, strings.Join(Formats, ", "))
+}
+
+func Format() string {
+	return format
+}
+
+// IsTable reports whether output is meant for humans rather than scripts
+func IsTable() bool {
+	return format == FormatTable
+}
+
+// Print renders a single value in the selected output format. Table output
+// falls back to indented JSON since arbitrary objects don't map onto columns.
+func Print(val any) {
+	switch format {
+	case FormatJson, FormatTable:
+		PrintJson(val)
+	case FormatNdjson:
+		printNdjson(val)
+	case FormatYaml:
+		printYaml(val)
+	case FormatCsv:
+		headers, row := flatten(val)
+		w := csv.NewWriter(os.Stdout)
+		_ = w.Write(headers)
+		_ = w.Write(row)
+		w.Flush()
+	}
+}
+
+// PrintResult prints a human-readable message for table output, or the
+// result value itself for any machine-readable format.
+func PrintResult(msg string, val any) {
+	if IsTable() {
+		PrintMsg(msg)
+		return
+	}
+	Print(val)
+}
+
+// ListPrinter streams a list of items in the selected output format. Table
+// output is buffered and rendered on Flush; every other format writes each
+// item as soon as it is added.
+type ListPrinter struct {
+	tbl     *table.Table
+	csv     *csv.Writer
+	count   int
+	cursors bool
+	before  string
+	after   string
+}
+
+func NewListPrinter(width int, headers ...string) *ListPrinter {
+	p := &ListPrinter{}
+	switch format {
+	case FormatTable:
+		tableHeaders := make([]string, len(headers))
+		for i, h := range headers {
+			tableHeaders[i] = TableHeader(h)
+		}
+		p.tbl = NewTable(width).Headers(tableHeaders...)
+	case FormatCsv:
+		p.csv = csv.NewWriter(os.Stdout)
+		_ = p.csv.Write(headers)
+	}
+	return p
+}
+
+// Add writes an item. The row is used for table and CSV output and must line
+// up with the headers; item is used for JSON, YAML and NDJSON output.
+func (p *ListPrinter) Add(item any, row ...string) {
+	switch format {
+	case FormatTable:
+		p.tbl.Row(row...)
+	case FormatCsv:
+		_ = p.csv.Write(row)
+		p.csv.Flush()
+	case FormatNdjson:
+		printNdjson(item)
+	case FormatJson:
+		bytes, err := json.MarshalIndent(item, "    ", "    ")
+		if err != nil {
+			PrintErrAndExit(err.Error())
+		}
+		if p.count == 0 {
+			fmt.Print("[\n    ")
+		} else {
+			fmt.Print(",\n    ")
+		}
+		fmt.Print(string(bytes))
+	case FormatYaml:
+		// A one-element sequence renders as a single "- " entry, so
+		// consecutive entries form one valid YAML list.
+		printYaml([]any{item})
+	}
+	p.count++
+}
+
+// SetCursors records the pagination cursors shown after a table
+func (p *ListPrinter) SetCursors(before string, after string) {
+	p.cursors = true
+	p.before = before
+	p.after = after
+}
+
+func (p *ListPrinter) Flush() {
+	switch format {
+	case FormatTable:
+		PrintMsg(p.tbl.Render())
+		if p.cursors {
+			PrintMsg(fmt.Sprintf("Before: %s", p.before))
+			PrintMsg(fmt.Sprintf("After: %s", p.after))
+		}
+	case FormatCsv:
+		p.csv.Flush()
+	case FormatJson:
+		if p.count == 0 {
+			fmt.Println("[]")
+		} else {
+			fmt.Print("\n]\n")
+		}
+	case FormatYaml:
+		if p.count == 0 {
+			fmt.Println("[]")
+		}
+	}
+}
+
+func printNdjson(val any) {
+	bytes, err := json.Marshal(val)
+	if err != nil {
+		PrintErrAndExit(err.Error())
+	}
+	fmt.Printf("%s\n", string(bytes))
+}
+
+func printYaml(val any) {
+	generic, err := toGeneric(val)
+	if err != nil {
+		PrintErrAndExit(err.Error())
+	}
+	bytes, err := yaml.Marshal(generic)
+	if err != nil {
+		PrintErrAndExit(err.Error())
+	}
+	fmt.Print(string(bytes))
+}
+
+// toGeneric round-trips a value through JSON so that YAML and CSV output use
+// the same field names as JSON output
+func toGeneric(val any) (any, error) {
+	bytes, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	err = json.Unmarshal(bytes, &generic)
+	return generic, err
+}
+
+// flatten converts a value into a single CSV header and row. Nested values are
+// encoded as compact JSON.
+func flatten(val any) ([]string, []string) {
+	generic, err := toGeneric(val)
+	if err != nil {
+		PrintErrAndExit(err.Error())
+	}
+	fields, ok := generic.(map[string]any)
+	if !ok {
+		return []string{"value"}, []string{cell(generic)}
+	}
+
+	headers := make([]string, 0, len(fields))
+	for key := range fields {
+		headers = append(headers, key)
+	}
+	sort.Strings(headers)
+	row := make([]string, len(headers))
+	for i, key := range headers {
+		row[i] = cell(fields[key])
+	}
+	return headers, row
+}
+
+func cell(val any) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			PrintErrAndExit(err.Error())
+		}
+		return string(bytes)
+	default:
+		return fmt.Sprint(v)
+	}
+}
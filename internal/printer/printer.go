@@ -16,6 +16,7 @@ var GreenText = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render
 var RedText = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render
 var YellowText = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFCC00")).Render
 var TableHeader = YellowText
+var Banner = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#FFFFFF")).Background(lipgloss.Color("#FF0000")).Padding(0, 1).Render
 
 func init() {
 	if runtime.GOOS == "windows" {
@@ -32,10 +33,25 @@ func PrintJson(val any) {
 	fmt.Printf("%s\n", string(bytes))
 }
 
+// PrintMsg prints a status message. Messages go to stderr when a
+// machine-readable output format is selected so they don't corrupt stdout.
 func PrintMsg(msg string) {
+	if !IsTable() {
+		_, _ = fmt.Fprintln(os.Stderr, msg)
+		return
+	}
 	fmt.Printf("%s\n", msg)
 }
 
+func PrintWarning(msg string) {
+	_, _ = fmt.Fprintln(os.Stderr, YellowText("Warning:"), msg)
+}
+
+// PrintBanner prints a highlighted message to stderr, e.g. to warn about the environment a command runs against
+func PrintBanner(msg string) {
+	_, _ = fmt.Fprintln(os.Stderr, Banner(msg))
+}
+
 func PrintErrAndExit(msg string) {
 	_, _ = fmt.Fprintln(os.Stderr, "Error:", msg)
 	os.Exit(1)